@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// parseInterspersed parses fs allowing flags after positional arguments,
+// e.g. `pomme task add "Write docs" --estimate 3`. It returns the positional
+// arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseDate accepts YYYY-MM-DD, "today" or "yesterday" and returns the date
+// in YYYY-MM-DD form.
+func parseDate(s string) (string, error) {
+	now := time.Now()
+	switch strings.ToLower(s) {
+	case "today":
+		return now.Format("2006-01-02"), nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1).Format("2006-01-02"), nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q (use YYYY-MM-DD, today or yesterday)", s)
+	}
+	return t.Format("2006-01-02"), nil
+}
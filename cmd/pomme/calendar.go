@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/philleif/pomme/internal/client"
+	"github.com/philleif/pomme/internal/sparkline"
+	"github.com/philleif/pomme/internal/theme"
+)
+
+func runCalendar(args []string) {
+	fs := flag.NewFlagSet("calendar", flag.ExitOnError)
+	weeks := fs.Int("weeks", 53, "Number of weeks to show, ending with this one")
+	style := fs.String("style", "ansi", "Cells: ansi (colored; braille when not a terminal), braille (plain) or kitty (graphics)")
+	asJSON := fs.Bool("json", false, "Print the days as JSON")
+	fs.Parse(args)
+
+	s, err := sparkline.ParseCalendarStyle(*style)
+	exitOnError(err)
+	palette := loadTheme(clientConfig()).Palette()
+	// Colored cells are escape codes, which only a terminal shows.
+	if s == sparkline.CalendarANSI && (theme.NoColor() || !term.IsTerminal(os.Stdout.Fd())) {
+		s = sparkline.CalendarBraille
+	}
+
+	c := client.New()
+	ensureDaemon(c, false)
+	days, err := c.Calendar(*weeks)
+	exitOnError(err)
+
+	if *asJSON {
+		data, _ := json.MarshalIndent(days, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	total := 0
+	for _, d := range days {
+		total += d.Count
+	}
+	fmt.Printf("%d pomodoros in the last %d weeks\n\n", total, *weeks)
+	fmt.Print(sparkline.RenderCalendar(days, s, palette))
+	fmt.Println()
+	fmt.Println(sparkline.CalendarLegend(s, palette))
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/chart"
+	"github.com/philleif/pomme/internal/client"
+	"github.com/philleif/pomme/internal/sparkline"
+	"github.com/philleif/pomme/internal/theme"
+)
+
+func runChart(args []string) {
+	cfg := effectiveConfig().Config
+
+	fs := flag.NewFlagSet("chart", flag.ExitOnError)
+	kind := fs.String("type", "week", "Chart: week (last 7 days), month (this month) or heatmap")
+	out := fs.String("out", "", "Write to this file; the extension (.svg or .png) picks the format")
+	format := fs.String("format", "", "svg or png; needed when writing to stdout")
+	width := fs.Int("width", cfg.ChartWidth, "Width in pixels")
+	height := fs.Int("height", cfg.ChartHeight, "Height in pixels")
+	themeCfg := clientConfig()
+	themeName := fs.String("theme", "", "Theme: "+strings.Join(themeCfg.Themes.Names(), ", ")+"; default the configured theme")
+	scale := fs.String("scale", cfg.GraphScale, "Bar height: auto (fit the busiest day) or goal (fit the goal and cap busier days)")
+	transparent := fs.Bool("transparent", false, "Leave out the background, for pages with their own")
+	title := fs.String("title", "", "Title; default describes the chart, \"none\" for no title")
+	from := fs.String("from", "", "Heatmap: first date to include (YYYY-MM-DD, today, yesterday); default 4 weeks ago")
+	to := fs.String("to", "today", "Heatmap: last date to include (YYYY-MM-DD, today, yesterday)")
+	fs.Parse(args)
+
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*out), ".")
+	}
+	if *format == "" {
+		exitOnError(fmt.Errorf("use --out chart.svg, --out chart.png or --format"))
+	}
+	f, err := chart.ParseFormat(*format)
+	exitOnError(err)
+
+	// theme_colors adjusts the configured theme, not one picked here.
+	if *themeName != "" && !strings.EqualFold(*themeName, themeCfg.Theme) {
+		themeCfg.Theme, themeCfg.ThemeColors = *themeName, ""
+	}
+	t, err := theme.Load(themeCfg.Theme, themeCfg.Themes, themeCfg.ThemeColors)
+	exitOnError(err)
+	opts := chart.Options{Width: *width, Height: *height, Theme: t.Chart()}
+	if *transparent {
+		opts.Theme.Background = color.NRGBA{}
+	}
+	exitOnError(opts.Validate())
+	barScale, err := sparkline.ParseScale(*scale)
+	exitOnError(err)
+
+	c := client.New()
+	ensureDaemon(c, false)
+
+	var ch chart.Chart
+	switch *kind {
+	case "week":
+		days, err := c.Calendar(2)
+		exitOnError(err)
+		ch = weekChart(days[max(len(days)-7, 0):])
+	case "month":
+		days, err := c.Calendar(6)
+		exitOnError(err)
+		ch = monthChart(days, time.Now())
+	case "heatmap":
+		if *from == "" {
+			*from = time.Now().AddDate(0, 0, -27).Format("2006-01-02")
+		}
+		fromDate, err := parseDate(*from)
+		exitOnError(err)
+		toDate, err := parseDate(*to)
+		exitOnError(err)
+		heatmap, err := c.Heatmap(fromDate, toDate)
+		exitOnError(err)
+		ch = heatmapChart(heatmap.Rows(), fromDate, toDate)
+	default:
+		exitOnError(fmt.Errorf("unknown chart type %q (use week, month or heatmap)", *kind))
+	}
+	ch.Scale = barScale
+	switch *title {
+	case "":
+	case "none":
+		ch.Title = ""
+	default:
+		ch.Title = *title
+	}
+
+	dest := os.Stdout
+	if *out != "" && *out != "-" {
+		dest, err = os.Create(*out)
+		exitOnError(err)
+		defer dest.Close()
+	}
+	buf := bufio.NewWriter(dest)
+	exitOnError(chart.Write(buf, ch, opts, f))
+	exitOnError(buf.Flush())
+
+	if dest != os.Stdout {
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", *out)
+	}
+}
+
+func weekChart(days []sparkline.CalendarDay) chart.Chart {
+	ch := chart.Chart{}
+	total := 0
+	for _, d := range days {
+		t, _ := time.ParseInLocation("2006-01-02", d.Date, time.Local)
+		ch.Bars = append(ch.Bars, chart.Bar{Label: t.Format("Mon"), Value: d.Count, Goal: d.Goal})
+		total += d.Count
+	}
+	ch.Title = fmt.Sprintf("Last 7 days: %d pomodoros", total)
+	return ch
+}
+
+// monthChart has a bar for every day of now's month; days still to come
+// are empty.
+func monthChart(days []sparkline.CalendarDay, now time.Time) chart.Chart {
+	byDate := make(map[string]sparkline.CalendarDay, len(days))
+	for _, d := range days {
+		byDate[d.Date] = d
+	}
+
+	ch := chart.Chart{}
+	total := 0
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		var label string
+		if d.Day() == 1 || d.Day()%5 == 0 {
+			label = fmt.Sprint(d.Day())
+		}
+		day := byDate[d.Format("2006-01-02")]
+		ch.Bars = append(ch.Bars, chart.Bar{Label: label, Value: day.Count, Goal: day.Goal})
+		total += day.Count
+	}
+	ch.Title = fmt.Sprintf("%s: %d pomodoros", first.Format("January 2006"), total)
+	return ch
+}
+
+func heatmapChart(rows [][]int, from, to string) chart.Chart {
+	hours := make([]string, 24)
+	for h := range hours {
+		hours[h] = fmt.Sprintf("%02d", h)
+	}
+	return chart.Chart{
+		Title:     fmt.Sprintf("Pomodoros by start time, %s to %s", from, to),
+		Heat:      rows,
+		RowLabels: []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+		ColLabels: hours,
+	}
+}
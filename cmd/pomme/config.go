@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/philleif/pomme/internal/client"
+	"github.com/philleif/pomme/internal/config"
+)
+
+func runConfig(args []string) {
+	if len(args) == 0 {
+		configUsage()
+		os.Exit(2)
+	}
+
+	switch args[0] {
+	case "validate":
+		path := config.ConfigPath()
+		if len(args) > 1 {
+			path = args[1]
+		}
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) && len(args) == 1 {
+			fmt.Printf("%s: not found, defaults in use\n", path)
+			return
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := config.Parse(data); err != nil {
+			if verr, ok := err.(*config.ValidationError); ok {
+				verr.Path = path
+			}
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: OK\n", path)
+
+	case "get":
+		if len(args) != 2 {
+			configUsage()
+			os.Exit(2)
+		}
+		resolved := effectiveConfig()
+		value, err := resolved.Config.Get(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(value)
+
+	case "set":
+		if len(args) != 3 {
+			configUsage()
+			os.Exit(2)
+		}
+		cfg := loadConfigOrExit()
+		if err := cfg.Set(args[1], args[2]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := config.Save(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		value, _ := cfg.Get(args[1])
+		fmt.Printf("%s = %s\n", args[1], value)
+		reloadDaemon()
+		if origin := effectiveConfig().Origins[args[1]]; origin == config.OriginEnv || origin == config.OriginFlag {
+			fmt.Fprintf(os.Stderr, "Note: %s is currently overridden by %s\n", args[1], origin)
+		}
+
+	case "list":
+		showOrigin := len(args) > 1 && (args[1] == "--origin" || args[1] == "-origin")
+		resolved := effectiveConfig()
+		for _, key := range config.Keys() {
+			value, _ := resolved.Config.Get(key)
+			if showOrigin {
+				fmt.Printf("%-30s %-10s %s\n", key, value, originLabel(key, resolved.Origins[key]))
+			} else {
+				fmt.Printf("%-30s %s\n", key, value)
+			}
+		}
+
+	case "edit":
+		editConfig()
+		reloadDaemon()
+
+	default:
+		configUsage()
+		os.Exit(2)
+	}
+}
+
+func configUsage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  pomme config list [--origin]")
+	fmt.Fprintln(os.Stderr, "  pomme config get <key>")
+	fmt.Fprintln(os.Stderr, "  pomme config set <key> <value>")
+	fmt.Fprintln(os.Stderr, "  pomme config edit")
+	fmt.Fprintln(os.Stderr, "  pomme config validate [file]")
+}
+
+func loadConfigOrExit() config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config (fix with 'pomme config edit'):\n%v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// effectiveConfig returns the running daemon's config when there is one,
+// since that is what is actually in effect, and otherwise resolves locally.
+func effectiveConfig() config.Resolved {
+	c := client.New()
+	if c.IsRunning() {
+		if resolved, err := c.Config(); err == nil {
+			return *resolved
+		}
+	}
+	resolved, err := config.Resolve(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config (fix with 'pomme config edit'):\n%v\n", err)
+		os.Exit(1)
+	}
+	return resolved
+}
+
+func originLabel(key string, origin config.Origin) string {
+	switch origin {
+	case config.OriginEnv:
+		return "env (" + config.EnvName(key) + ")"
+	case config.OriginFlag:
+		return "flag (--" + config.FlagName(key) + ")"
+	case config.OriginFile:
+		return "file (" + config.ConfigPath() + ")"
+	default:
+		return string(origin)
+	}
+}
+
+// editConfig opens the config file in $VISUAL or $EDITOR and re-opens it
+// until the result validates or the user gives up.
+func editConfig() {
+	// Make sure the file exists before handing it to the editor.
+	config.Load()
+	path := config.ConfigPath()
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	for {
+		parts := strings.Fields(editor)
+		cmd := exec.Command(parts[0], append(parts[1:], path)...)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: editor failed: %v\n", err)
+			os.Exit(1)
+		}
+
+		_, err := config.Load()
+		if err == nil {
+			fmt.Printf("%s: OK\n", path)
+			return
+		}
+
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprint(os.Stderr, "Config is invalid. Edit again? [Y/n] ")
+		// No answer (end of input, as when not run from a terminal) is a
+		// no, or we would open the editor forever.
+		answer, err := in.ReadString('\n')
+		if err != nil {
+			fmt.Fprintln(os.Stderr)
+			os.Exit(1)
+		}
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "n") {
+			os.Exit(1)
+		}
+	}
+}
+
+// reloadDaemon applies the saved config immediately when a daemon is running.
+func reloadDaemon() {
+	c := client.New()
+	if !c.IsRunning() {
+		return
+	}
+	if _, err := c.ReloadConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "Daemon reload failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("Applied to running daemon")
+}
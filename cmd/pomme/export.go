@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/philleif/pomme/internal/export"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "csv", "Output format: csv, json, jsonl or ics")
+	from := fs.String("from", "", "First date to include (YYYY-MM-DD, today, yesterday)")
+	to := fs.String("to", "", "Last date to include (YYYY-MM-DD, today, yesterday)")
+	out := fs.String("out", "", "Write to this file instead of stdout")
+	fs.Parse(args)
+
+	f, err := export.ParseFormat(*format)
+	exitOnError(err)
+
+	var fromDate, toDate string
+	if *from != "" {
+		fromDate, err = parseDate(*from)
+		exitOnError(err)
+	}
+	if *to != "" {
+		toDate, err = parseDate(*to)
+		exitOnError(err)
+	}
+	exitOnError(export.CheckRange(fromDate, toDate))
+
+	// Read the database directly rather than through the daemon so large
+	// histories stream instead of being buffered into one socket response.
+	store, err := storage.New()
+	exitOnError(err)
+	defer store.Close()
+
+	dest := os.Stdout
+	if *out != "" {
+		dest, err = os.Create(*out)
+		exitOnError(err)
+		defer dest.Close()
+	}
+	buf := bufio.NewWriter(dest)
+
+	var opts export.Options
+	if f == export.FormatICS {
+		opts.WorkDuration = effectiveConfig().Config.WorkDurationTime()
+	}
+	w, err := export.NewWriter(buf, f, opts)
+	exitOnError(err)
+
+	count := 0
+	err = store.Sessions(fromDate, toDate, func(s storage.Session) error {
+		count++
+		return w.Write(s)
+	})
+	exitOnError(err)
+	exitOnError(w.Close())
+	exitOnError(buf.Flush())
+
+	if *out != "" {
+		fmt.Fprintf(os.Stderr, "Exported %d sessions to %s\n", count, *out)
+	}
+}
+
+func exitOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
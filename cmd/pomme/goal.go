@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/philleif/pomme/internal/config"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func runGoal(args []string) {
+	if len(args) == 0 {
+		args = []string{"show"}
+	}
+
+	switch args[0] {
+	case "show":
+		if len(args) > 2 {
+			goalUsage()
+			os.Exit(2)
+		}
+		date := "today"
+		if len(args) == 2 {
+			date = args[1]
+		}
+		day, err := parseDate(date)
+		exitOnError(err)
+		showGoals(day)
+
+	case "list":
+		listGoals(loadConfigOrExit())
+
+	case "set":
+		if len(args) != 3 {
+			goalUsage()
+			os.Exit(2)
+		}
+		goal, err := config.ParseGoal(args[2])
+		exitOnError(err)
+		updateGoals(args[1], &goal)
+
+	case "unset":
+		if len(args) != 2 {
+			goalUsage()
+			os.Exit(2)
+		}
+		updateGoals(args[1], nil)
+
+	default:
+		goalUsage()
+		os.Exit(2)
+	}
+}
+
+func goalUsage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  pomme goal [show [date]]")
+	fmt.Fprintln(os.Stderr, "  pomme goal list")
+	fmt.Fprintln(os.Stderr, "  pomme goal set <date|weekday|default> <n|off>")
+	fmt.Fprintln(os.Stderr, "  pomme goal unset <date|weekday>")
+}
+
+// updateGoals sets (or, with a nil goal, removes) the goal for target: a
+// date, a weekday, or "default" for daily_goal. It saves the config and
+// applies it to a running daemon.
+func updateGoals(target string, goal *int) {
+	cfg := loadConfigOrExit()
+
+	var what string
+	if target == "default" {
+		if goal == nil {
+			fmt.Fprintln(os.Stderr, "Error: the default goal can't be unset")
+			os.Exit(1)
+		}
+		cfg.DailyGoal = *goal
+		what = "default goal"
+	} else if days, err := config.ParseWeekdays(target); err == nil && len(days) == 1 {
+		if cfg.WeekdayGoals == nil {
+			cfg.WeekdayGoals = config.WeekdayGoals{}
+		}
+		if goal != nil {
+			cfg.WeekdayGoals[days[0]] = *goal
+		} else {
+			delete(cfg.WeekdayGoals, days[0])
+		}
+		what = days[0].String() + " goal"
+	} else {
+		date, err := parseDate(target)
+		exitOnError(err)
+		if cfg.GoalOverrides == nil {
+			cfg.GoalOverrides = config.GoalOverrides{}
+		}
+		if goal != nil {
+			cfg.GoalOverrides[date] = *goal
+		} else {
+			delete(cfg.GoalOverrides, date)
+		}
+		what = "goal for " + date
+	}
+
+	exitOnError(cfg.Validate())
+	exitOnError(config.Save(cfg))
+	if goal == nil {
+		fmt.Printf("Removed %s\n", what)
+	} else {
+		fmt.Printf("Set %s to %s\n", what, goalLabel(storage.DayGoal{Goal: *goal, Off: *goal == 0}))
+	}
+	reloadDaemon()
+}
+
+// showGoals prints the goal of the week starting at date.
+func showGoals(date string) {
+	cfg := effectiveConfig().Config
+	store, err := storage.New()
+	exitOnError(err)
+	defer store.Close()
+
+	plan, err := goalPlan(store, cfg)
+	exitOnError(err)
+
+	start, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+	for i := 0; i < 7; i++ {
+		day := start.AddDate(0, 0, i).Format("2006-01-02")
+		fmt.Printf("%s %s  %s\n", day, start.AddDate(0, 0, i).Format("Mon"), goalLabel(plan.On(day)))
+	}
+}
+
+// listGoals prints the configured goals: the default, per weekday and for
+// specific dates.
+func listGoals(cfg config.Config) {
+	fmt.Printf("%-12s %d\n", "default", cfg.DailyGoal)
+
+	weekdays := cfg.WeekdayGoals
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		if goal, ok := weekdays[day]; ok {
+			fmt.Printf("%-12s %s\n", day, goalLabel(storage.DayGoal{Goal: goal, Off: goal == 0}))
+		}
+	}
+	for _, day := range cfg.RestDays {
+		if _, ok := weekdays[day]; !ok {
+			fmt.Printf("%-12s %d (rest day)\n", day, cfg.DailyGoal)
+		}
+	}
+
+	overrides := cfg.GoalOverrides
+	dates := make([]string, 0, len(overrides))
+	for date := range overrides {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	for _, date := range dates {
+		goal := overrides[date]
+		fmt.Printf("%-12s %s\n", date, goalLabel(storage.DayGoal{Goal: goal, Off: goal == 0}))
+	}
+}
+
+// goalPlan decides each day's goal from cfg and the recorded goal history.
+func goalPlan(store *storage.Storage, cfg config.Config) (storage.GoalPlan, error) {
+	return store.GoalPlan(
+		storage.GoalChange{From: time.Now().Format("2006-01-02"), Goal: cfg.DailyGoal, Weekdays: cfg.WeekdayGoals, RestDays: cfg.RestDays},
+		cfg.GoalOverrides,
+	)
+}
+
+func goalLabel(g storage.DayGoal) string {
+	switch {
+	case g.Off && g.Goal == 0:
+		return "off"
+	case g.Off:
+		return fmt.Sprintf("%d (rest day)", g.Goal)
+	}
+	return fmt.Sprint(g.Goal)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/philleif/pomme/internal/client"
+	"github.com/philleif/pomme/internal/sparkline"
+	"github.com/philleif/pomme/internal/theme"
+)
+
+func runHeatmap(args []string) {
+	fs := flag.NewFlagSet("heatmap", flag.ExitOnError)
+	from := fs.String("from", "", "First date to include (YYYY-MM-DD, today, yesterday); default 4 weeks ago")
+	to := fs.String("to", "today", "Last date to include (YYYY-MM-DD, today, yesterday)")
+	style := fs.String("style", "block", "Cell shading: block, braille or color (24-bit)")
+	asJSON := fs.Bool("json", false, "Print the weekday × hour matrix as JSON")
+	fs.Parse(args)
+
+	s, err := sparkline.ParseHeatmapStyle(*style)
+	exitOnError(err)
+	palette := loadTheme(clientConfig()).Palette()
+	if s == sparkline.HeatmapColor && theme.NoColor() {
+		s = sparkline.HeatmapBlock
+	}
+
+	if *from == "" {
+		*from = time.Now().AddDate(0, 0, -27).Format("2006-01-02")
+	}
+	fromDate, err := parseDate(*from)
+	exitOnError(err)
+	toDate, err := parseDate(*to)
+	exitOnError(err)
+
+	c := client.New()
+	ensureDaemon(c, false)
+	heatmap, err := c.Heatmap(fromDate, toDate)
+	exitOnError(err)
+
+	if *asJSON {
+		data, _ := json.MarshalIndent(heatmap, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	days := []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+	hours := make([]string, 24)
+	for h := range hours {
+		hours[h] = fmt.Sprintf("%02d", h)
+	}
+	fmt.Printf("Pomodoros by start time, %s to %s\n\n", fromDate, toDate)
+	fmt.Print(sparkline.RenderHeatmap(heatmap.Rows(), days, hours, s, palette))
+	fmt.Println()
+	fmt.Println(sparkline.HeatmapLegend(heatmap.Max, s, palette))
+}
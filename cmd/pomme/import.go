@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/philleif/pomme/internal/client"
+	"github.com/philleif/pomme/internal/importer"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func runImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "pomme", "Input format: pomme, csv, timewarrior or toggl")
+	mapFlag := fs.String("map", "", "CSV column mapping, e.g. started_at=Start,completed_at=End,task=Title")
+	dryRun := fs.Bool("dry-run", false, "Show what would be imported without writing anything")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: pomme import [--format f] [--map m] [--dry-run] [file|-]")
+		fs.PrintDefaults()
+	}
+	files, err := parseInterspersed(fs, args)
+	exitOnError(err)
+	if len(files) > 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	f, err := importer.ParseFormat(*format)
+	exitOnError(err)
+	mapping, err := importer.ParseMapping(*mapFlag)
+	exitOnError(err)
+	if len(mapping) > 0 && f != importer.FormatCSV {
+		exitOnError(fmt.Errorf("--map only applies to --format csv"))
+	}
+
+	var in io.Reader = os.Stdin
+	name := "stdin"
+	if len(files) == 1 && files[0] != "-" {
+		file, err := os.Open(files[0])
+		exitOnError(err)
+		defer file.Close()
+		in, name = file, files[0]
+	}
+
+	var work time.Duration
+	if f == importer.FormatTimewarrior || f == importer.FormatToggl {
+		work = effectiveConfig().Config.WorkDurationTime()
+	}
+	sessions, err := importer.Read(in, f, mapping, work)
+	if err != nil {
+		exitOnError(fmt.Errorf("%s: %w", name, err))
+	}
+
+	// Like export, use the database directly. A running daemon reads history
+	// from it on demand; only its count for today and its streak are kept in
+	// memory, and a reload refreshes the streak.
+	store, err := storage.New()
+	exitOnError(err)
+	defer store.Close()
+
+	result, err := store.ImportSessions(sessions, *dryRun)
+	exitOnError(err)
+
+	verb := "Imported"
+	if *dryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d of %d sessions from %s (%d duplicates skipped, %d new tasks)\n",
+		verb, result.Imported, len(sessions), name, result.Duplicates, result.TasksCreated)
+
+	if c := client.New(); result.Imported > 0 && !*dryRun && c.IsRunning() {
+		c.InvalidateStats()
+	}
+}
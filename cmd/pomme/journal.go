@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/philleif/pomme/internal/config"
+	"github.com/philleif/pomme/internal/journal"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func runJournal(args []string) {
+	resolved, err := config.Resolve(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config (fix with 'pomme config edit'):\n%v\n", err)
+		os.Exit(1)
+	}
+	cfg := resolved.Config
+
+	fs := flag.NewFlagSet("journal", flag.ExitOnError)
+	date := fs.String("date", "today", "Day to write (YYYY-MM-DD, today, yesterday)")
+	format := fs.String("format", cfg.JournalFormat, "Output format: md or org")
+	templatePath := fs.String("template", cfg.JournalTemplate, "Go template file to use instead of the built-in layout")
+	appendPath := fs.String("append", "", "Append to this file instead of printing")
+	printTemplate := fs.Bool("print-template", false, "Print the built-in template for --format, to start a custom one")
+	fs.Parse(args)
+
+	f, err := journal.ParseFormat(*format)
+	exitOnError(err)
+
+	if *printTemplate {
+		text, err := journal.DefaultTemplate(f)
+		exitOnError(err)
+		fmt.Print(text)
+		return
+	}
+
+	day, err := parseDate(*date)
+	exitOnError(err)
+	tmpl, err := journal.Template(f, *templatePath)
+	exitOnError(err)
+
+	store, err := storage.New()
+	exitOnError(err)
+	defer store.Close()
+
+	plan, err := goalPlan(store, cfg)
+	exitOnError(err)
+	entry, err := journal.Load(store, day, plan.On(day))
+	exitOnError(err)
+
+	if *appendPath != "" {
+		exitOnError(journal.Append(*appendPath, tmpl, entry))
+		fmt.Fprintf(os.Stderr, "Appended %s to %s\n", day, *appendPath)
+		return
+	}
+	exitOnError(journal.Render(os.Stdout, tmpl, entry))
+}
@@ -6,30 +6,87 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/philleif/pomme/internal/client"
+	"github.com/philleif/pomme/internal/config"
 	"github.com/philleif/pomme/internal/daemon"
 	"github.com/philleif/pomme/internal/mcp"
 	"github.com/philleif/pomme/internal/menubar"
+	"github.com/philleif/pomme/internal/paths"
 	"github.com/philleif/pomme/internal/sparkline"
-	"github.com/philleif/pomme/internal/storage"
+	"github.com/philleif/pomme/internal/statusline"
 	"github.com/philleif/pomme/internal/tui"
 )
 
+// configOverrides collects per-key config flags such as --work 1m.
+var configOverrides = map[string]string{}
+
 func main() {
+	os.Args = applyHomeFlag(os.Args)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "config":
+			runConfig(os.Args[2:])
+			return
+		case "task":
+			runTask(os.Args[2:])
+			return
+		case "export":
+			runExport(os.Args[2:])
+			return
+		case "import":
+			runImport(os.Args[2:])
+			return
+		case "journal":
+			runJournal(os.Args[2:])
+			return
+		case "stats":
+			runStats(os.Args[2:])
+			return
+		case "goal":
+			runGoal(os.Args[2:])
+			return
+		case "report":
+			runReport(os.Args[2:])
+			return
+		case "heatmap":
+			runHeatmap(os.Args[2:])
+			return
+		case "calendar":
+			runCalendar(os.Args[2:])
+			return
+		case "chart":
+			runChart(os.Args[2:])
+			return
+		}
+	}
+
 	daemonMode := flag.Bool("daemon", false, "Run as daemon (menu bar only)")
 	mcpMode := flag.Bool("mcp", false, "Run as MCP server (stdio)")
 	statusMode := flag.Bool("status", false, "Print status line (for tmux)")
+	statusFormat := flag.String("format", "default", "Status line format for --status: a Go template or one of "+strings.Join(statusline.PresetNames(), ", "))
+	tmuxMode := flag.Bool("tmux", false, "Print status line with tmux color markup from the theme (--status --format tmux)")
 	simpleBarMode := flag.Bool("simplebar", false, "Print status for simple-bar widget")
 	startCmd := flag.Bool("start", false, "Start/resume timer")
 	pauseCmd := flag.Bool("pause", false, "Pause timer")
 	skipCmd := flag.Bool("skip", false, "Skip to next phase")
 	resetCmd := flag.Bool("reset", false, "Reset timer")
 	toggleBlockCmd := flag.Bool("toggle-block", false, "Toggle Messages blocking")
+	reloadCmd := flag.Bool("reload", false, "Reload config in the running daemon")
+	interruptCmd := flag.String("interrupt", "", "Log an interruption of the running work interval: internal or external (--note adds detail)")
+	noteCmd := flag.String("note", "", "Add a note to the current or last work interval (#hashtags become tags)")
+	tagFlag := flag.String("tag", "", "Comma-separated tags for --note, or a tag to filter --stats by")
 	statsCmd := flag.Bool("stats", false, "Print today's stats")
-	graphCmd := flag.Bool("graph", false, "Show graphical sparkline (Kitty protocol for Ghostty)")
+	graphCmd := flag.Bool("graph", false, "Show graphical sparkline (kitty, sixel or iTerm2 images; see graph_renderer)")
+
+	// Handled by applyHomeFlag; registered here so it shows up in -help.
+	flag.String("home", "", "Keep config, data and socket in this directory (sets "+paths.HomeEnv+")")
+	config.RegisterFlags(flag.CommandLine, configOverrides)
 
 	flag.Parse()
 
@@ -47,32 +104,13 @@ func main() {
 		}
 
 	case *statusMode:
-		status, err := c.Status()
-		if err != nil {
-			fmt.Println("⏹ --:--")
-			os.Exit(0)
-		}
-		fmt.Println(status.StatusLine)
+		printStatus(c, *statusFormat)
+
+	case *tmuxMode:
+		printStatus(c, "tmux")
 
 	case *simpleBarMode:
-		status, err := c.Status()
-		if err != nil {
-			fmt.Println("⏹ --:--")
-			os.Exit(0)
-		}
-		// Compact format for simple-bar: icon time count
-		var icon string
-		switch {
-		case status.TimerState == "paused":
-			icon = "⏸"
-		case status.TimerState == "idle":
-			icon = "⏹"
-		case status.Phase == "work":
-			icon = "🍅"
-		default:
-			icon = "☕"
-		}
-		fmt.Printf("%s %s %s\n", icon, status.Remaining, subscript(status.IntervalsToday))
+		printStatus(c, "simplebar")
 
 	case *startCmd:
 		ensureDaemon(c, false)
@@ -123,15 +161,56 @@ func main() {
 			fmt.Println("Messages blocking: OFF")
 		}
 
+	case *reloadCmd:
+		ensureDaemon(c, false)
+		if _, err := c.ReloadConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Config reloaded")
+
+	case *interruptCmd != "":
+		ensureDaemon(c, false)
+		interruption, err := c.Interrupt(*interruptCmd, *noteCmd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Logged %s interruption\n", interruption.Kind)
+
+	case *noteCmd != "":
+		ensureDaemon(c, false)
+		result, err := c.Note(*noteCmd, splitTags(*tagFlag))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if result.Target == "current" {
+			fmt.Println("Note saved for the current work interval")
+		} else {
+			fmt.Println("Note added to the last work interval")
+		}
+		if len(result.Tags) > 0 {
+			fmt.Printf("Tags: #%s\n", strings.Join(result.Tags, " #"))
+		}
+
 	case *statsCmd:
 		ensureDaemon(c, false)
-		status, err := c.Status()
+		stats, err := c.Stats(*tagFlag)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Printf("Today: %d/%d intervals\n", status.IntervalsToday, status.DailyGoal)
-		fmt.Printf("Week:  %s\n", status.Sparkline)
+		goal := fmt.Sprint(stats.DailyGoal)
+		if stats.DayOff && stats.DailyGoal == 0 {
+			goal = "off"
+		}
+		if stats.Tag != "" {
+			fmt.Printf("Today: %d/%s intervals tagged #%s\n", stats.Today, goal, stats.Tag)
+		} else {
+			fmt.Printf("Today: %d/%s intervals\n", stats.Today, goal)
+		}
+		fmt.Printf("Week:  %s\n", stats.Sparkline)
 		// Dynamic day labels based on today
 		dayNames := []string{"S", "M", "T", "W", "T", "F", "S"}
 		today := int(time.Now().Weekday())
@@ -141,13 +220,46 @@ func main() {
 			fmt.Printf("%-3s", dayNames[dayIdx])
 		}
 		fmt.Println()
-		if len(status.WeekValues) > 0 {
+		if len(stats.WeekValues) > 0 {
 			fmt.Print("       ")
-			for _, v := range status.WeekValues {
+			for _, v := range stats.WeekValues {
 				fmt.Printf("%-3d", v)
 			}
 			fmt.Println()
 		}
+		if stats.Tag == "" && len(stats.WeekTags) > 0 {
+			var tags []string
+			for _, t := range stats.WeekTags {
+				tags = append(tags, fmt.Sprintf("#%s %d", t.Tag, t.Count))
+			}
+			fmt.Printf("Tags:  %s\n", strings.Join(tags, "  "))
+		}
+		if st := stats.Streaks; st.ActiveDays > 0 {
+			fmt.Printf("Streak: %s at goal (longest %d), %s with a pomodoro (longest %d)\n",
+				days(st.Current), st.Longest, days(st.ActiveCurrent), st.ActiveLongest)
+			var rates []string
+			for _, w := range st.Weeks {
+				if w.WorkDays > 0 {
+					rates = append(rates, fmt.Sprintf("%s %d/%d", w.Week[5:], w.GoalDays, w.WorkDays))
+				}
+			}
+			if len(rates) > 0 {
+				fmt.Printf("Goal:  %s days\n", strings.Join(rates, "  "))
+			}
+		}
+		if wi := stats.WeekInterruptions; wi.Total() > 0 {
+			fmt.Printf("Interruptions: %d this week (%d internal, %d external), %.1f per pomodoro\n",
+				wi.Total(), wi.Internal, wi.External, wi.PerInterval())
+		}
+		if len(stats.TodayNotes) > 0 {
+			fmt.Println()
+			fmt.Println("Notes:")
+			for _, n := range stats.TodayNotes {
+				if t, err := time.Parse(time.RFC3339, n.CompletedAt); err == nil {
+					fmt.Printf("  %s  %s\n", t.Format("15:04"), noteLine(n.Note, n.Tags))
+				}
+			}
+		}
 
 	case *graphCmd:
 		ensureDaemon(c, false)
@@ -158,15 +270,40 @@ func main() {
 		}
 		fmt.Printf("Today: %d/%d intervals\n", status.IntervalsToday, status.DailyGoal)
 		fmt.Println()
-		// Kitty graphics sparkline (works in Ghostty)
-		graph := sparkline.GenerateKittyGraphics(status.WeekValues, status.DailyGoal, 140, 40)
+		// The renderer depends on this terminal, not the daemon's, so it
+		// comes from this process's config and flags.
+		cfg := clientConfig()
+		renderer, err := sparkline.ParseRenderer(cfg.GraphRenderer)
+		exitOnError(err)
+		if renderer == sparkline.RendererAuto {
+			renderer = sparkline.DetectRenderer()
+		}
+		scale, err := sparkline.ParseScale(cfg.GraphScale)
+		exitOnError(err)
+
+		chart := sparkline.NewBarChart(status.WeekValues, status.DailyGoal, 140, 40)
+		if len(status.WeekGoals) == len(status.WeekValues) {
+			chart.Goals = status.WeekGoals
+		}
+		chart.Scale = scale
+		chart.PixelRatio = cfg.GraphPixelRatio
+		chart.Colors = loadTheme(cfg).BarColors()
+		graph := sparkline.GenerateGraph(chart, renderer)
+		if renderer == sparkline.RendererBlocks {
+			// Line the bars up with the day labels below.
+			graph = "       " + graph
+		}
 		fmt.Print(graph)
 		fmt.Println()
-		fmt.Println("       M  T  W  T  F  S  S")
+		days := make([]string, len(status.WeekValues))
+		for i := range days {
+			days[i] = time.Now().AddDate(0, 0, i-len(days)+1).Weekday().String()[:1]
+		}
+		fmt.Println("       " + strings.Join(days, "  "))
 
 	default:
 		ensureDaemon(c, false)
-		if err := tui.Run(); err != nil {
+		if err := tui.Run(loadTheme(clientConfig())); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
@@ -174,13 +311,17 @@ func main() {
 }
 
 func runDaemon() {
-	d, err := daemon.New()
+	d, err := daemon.New(configOverrides)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to start daemon: %v\n", err)
 		os.Exit(1)
 	}
 
-	socketPath := storage.SocketPath()
+	socketPath, err := paths.SocketPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to start socket server: %v\n", err)
+		os.Exit(1)
+	}
 	if err := d.Start(socketPath); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to start socket server: %v\n", err)
 		os.Exit(1)
@@ -191,42 +332,119 @@ func runDaemon() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+
 	mb := menubar.New(d)
 	go func() {
 		<-sigChan
 		d.Stop()
 		os.Exit(0)
 	}()
+	go func() {
+		for range hupChan {
+			if err := d.ReloadConfig(); err != nil {
+				fmt.Fprintf(os.Stderr, "Config reload failed: %v\n", err)
+			}
+		}
+	}()
 
 	mb.Run()
 }
 
-func subscript(n int) string {
-	if n == 0 {
-		return "₀"
+func splitTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.Split(s, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// noteLine shows a note followed by any tags it doesn't already mention.
+func noteLine(note string, tags []string) string {
+	line := note
+	for _, tag := range tags {
+		if !strings.Contains(strings.ToLower(note), "#"+tag) {
+			line += " #" + tag
+		}
 	}
-	digits := []rune{'₀', '₁', '₂', '₃', '₄', '₅', '₆', '₇', '₈', '₉'}
-	var result []rune
-	for n > 0 {
-		result = append([]rune{digits[n%10]}, result...)
-		n /= 10
+	return strings.TrimSpace(line)
+}
+
+// applyHomeFlag strips --home from args and exports it as POMME_HOME, so it
+// applies to subcommands too and is inherited by an auto-started daemon.
+func applyHomeFlag(args []string) []string {
+	out := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		var dir string
+		switch {
+		case (arg == "--home" || arg == "-home") && i+1 < len(args):
+			dir = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--home="):
+			dir = strings.TrimPrefix(arg, "--home=")
+		case strings.HasPrefix(arg, "-home="):
+			dir = strings.TrimPrefix(arg, "-home=")
+		default:
+			out = append(out, arg)
+			continue
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		os.Setenv(paths.HomeEnv, dir)
 	}
-	return string(result)
+	return out
+}
+
+// clientKeys are config keys read by this process rather than the daemon,
+// so flags for them take effect with a daemon already running.
+var clientKeys = map[string]bool{
+	"graph_renderer":    true,
+	"graph_scale":       true,
+	"graph_pixel_ratio": true,
+	"theme":             true,
+	"theme_colors":      true,
 }
 
 func ensureDaemon(c *client.Client, silent bool) {
 	if c.IsRunning() {
+		daemonFlags := 0
+		for key := range configOverrides {
+			if !clientKeys[key] {
+				daemonFlags++
+			}
+		}
+		if daemonFlags > 0 && !silent {
+			fmt.Fprintln(os.Stderr, "Daemon already running; config flags only apply when it starts")
+		}
 		return
 	}
 
+	if _, err := config.Resolve(configOverrides); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config, not starting daemon:\n%v\n", err)
+		os.Exit(1)
+	}
+
 	if !silent {
 		fmt.Println("Starting Pomme daemon...")
 	}
 
-	cmd := exec.Command(os.Args[0], "--daemon")
+	args := append([]string{"--daemon"}, config.Args(configOverrides)...)
+	cmd := exec.Command(os.Args[0], args...)
 	cmd.Stdout = nil
 	cmd.Stderr = nil
 	cmd.Stdin = nil
+	// Keep the daemon's errors, such as a journal it couldn't append to.
+	if path, err := paths.LogPath(); err == nil {
+		if f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err == nil {
+			defer f.Close()
+			cmd.Stderr = f
+		}
+	}
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		Setsid: true,
 	}
@@ -245,3 +463,10 @@ func ensureDaemon(c *client.Client, silent bool) {
 	fmt.Fprintln(os.Stderr, "Failed to start daemon")
 	os.Exit(1)
 }
+
+func days(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
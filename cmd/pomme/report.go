@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/config"
+	"github.com/philleif/pomme/internal/report"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func runReport(args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	week := fs.Bool("week", false, "Report on this week, Monday to Sunday (default)")
+	month := fs.Bool("month", false, "Report on this month")
+	span := fs.String("range", "", "Report on a range of days, FROM..TO (YYYY-MM-DD, today, yesterday)")
+	last := fs.Bool("last", false, "Report on the previous week, month or range instead")
+	format := fs.String("format", "text", "Output format: text, md or json")
+	fs.Parse(args)
+
+	f, err := report.ParseFormat(*format)
+	exitOnError(err)
+
+	now := time.Now()
+	var period report.Period
+	switch {
+	case *span != "":
+		if *week || *month {
+			exitOnError(fmt.Errorf("use only one of --week, --month and --range"))
+		}
+		from, to, ok := strings.Cut(*span, "..")
+		if !ok {
+			exitOnError(fmt.Errorf("invalid range %q (use FROM..TO)", *span))
+		}
+		from, err = parseDate(from)
+		exitOnError(err)
+		to, err = parseDate(to)
+		exitOnError(err)
+		period, err = report.Range(from, to)
+		exitOnError(err)
+	case *month:
+		if *week {
+			exitOnError(fmt.Errorf("use only one of --week, --month and --range"))
+		}
+		period = report.Month(now)
+	default:
+		period = report.Week(now)
+	}
+	if *last {
+		period = period.Previous()
+	}
+
+	resolved, err := config.Resolve(nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config (fix with 'pomme config edit'):\n%v\n", err)
+		os.Exit(1)
+	}
+
+	store, err := storage.New()
+	exitOnError(err)
+	defer store.Close()
+
+	plan, err := goalPlan(store, resolved.Config)
+	exitOnError(err)
+	r, err := report.Build(store, plan, period, now)
+	exitOnError(err)
+	exitOnError(report.Write(os.Stdout, r, f))
+}
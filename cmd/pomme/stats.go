@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/client"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	by := fs.String("by", "day", "Group by day, week, month, weekday, hour, weekday-hour, task or tag")
+	from := fs.String("from", "", "First date to include (YYYY-MM-DD, today, yesterday); default 6 days ago for --by day")
+	to := fs.String("to", "today", "Last date to include (YYYY-MM-DD, today, yesterday)")
+	tag := fs.String("tag", "", "Only count intervals with this tag")
+	task := fs.Int64("task", 0, "Only count intervals linked to this task id")
+	asJSON := fs.Bool("json", false, "Print JSON")
+	fs.Parse(args)
+
+	groupBy, err := storage.ParseGroupBy(*by)
+	exitOnError(err)
+
+	q := storage.StatsQuery{GroupBy: groupBy, Tag: *tag, TaskID: *task}
+	if *from == "" && groupBy == storage.GroupDay {
+		*from = time.Now().AddDate(0, 0, -6).Format("2006-01-02")
+	}
+	if *from != "" {
+		q.From, err = parseDate(*from)
+		exitOnError(err)
+	}
+	if *to != "" {
+		q.To, err = parseDate(*to)
+		exitOnError(err)
+	}
+	// Gaps only make sense to fill over a bounded range, and 168 weekday
+	// hours would be mostly empty rows.
+	q.Fill = groupBy != storage.GroupWeekdayHour &&
+		(q.From != "" || groupBy == storage.GroupWeekday || groupBy == storage.GroupHour)
+
+	c := client.New()
+	ensureDaemon(c, false)
+	buckets, err := c.StatsQuery(q)
+	exitOnError(err)
+
+	if *asJSON {
+		data, _ := json.MarshalIndent(buckets, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+	if len(buckets) == 0 {
+		fmt.Println("No pomodoros in this range")
+		return
+	}
+
+	width, most := len(groupBy), 0
+	for _, b := range buckets {
+		width = max(width, len([]rune(b.Label)))
+		most = max(most, b.Count)
+	}
+	header := strings.ToUpper(string(groupBy[:1])) + string(groupBy[1:])
+	fmt.Printf("%-*s  %9s  %7s\n", width, header, "Pomodoros", "Focus")
+	var count, minutes int
+	for _, b := range buckets {
+		bar := ""
+		if most > 0 {
+			bar = strings.Repeat("█", (b.Count*20+most-1)/most)
+		}
+		line := fmt.Sprintf("%-*s  %9d  %7s  %s", width, b.Label, b.Count, formatMinutes(b.FocusMinutes), bar)
+		fmt.Println(strings.TrimRight(line, " "))
+		if groupBy != storage.GroupTag {
+			count += b.Count
+			minutes += b.FocusMinutes
+		}
+	}
+	if groupBy != storage.GroupTag {
+		fmt.Printf("%-*s  %9d  %7s\n", width, "Total", count, formatMinutes(minutes))
+	}
+}
+
+// formatMinutes renders minutes as "45m" or "3h05m".
+func formatMinutes(m int) string {
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", m/60, m%60)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/philleif/pomme/internal/client"
+	"github.com/philleif/pomme/internal/config"
+	"github.com/philleif/pomme/internal/daemon"
+	"github.com/philleif/pomme/internal/statusline"
+	"github.com/philleif/pomme/internal/theme"
+)
+
+// printStatus prints the status line in format, a statusline preset or
+// template. A daemon that isn't running shows as offline rather than an
+// error, since status bars run this every few seconds.
+func printStatus(c *client.Client, format string) {
+	_, err := statusline.Parse(format)
+	exitOnError(err)
+
+	s := statusline.Offline()
+	t := statusTheme()
+	if status, err := c.Status(); err == nil {
+		s = statusModel(status)
+	}
+	s.Colors = t.Colors()
+	s.Colors["state"] = t.StateColor(s.State)
+	if s.State == "offline" {
+		s.Colors["state"] = t.Subtle
+	}
+
+	line, err := statusline.Format(s, format)
+	exitOnError(err)
+	fmt.Println(line)
+}
+
+// statusTheme is the configured theme, or the default one when the config
+// is invalid: a broken config shouldn't break every status bar. It only
+// reads the config, so polling never writes config.json.
+func statusTheme() theme.Theme {
+	if resolved, err := config.ResolveReadOnly(configOverrides); err == nil {
+		if t, err := theme.Load(resolved.Config.Theme, resolved.Config.Themes, resolved.Config.ThemeColors); err == nil {
+			return t
+		}
+	}
+	t, _ := theme.Load(theme.Default, nil, "")
+	return t
+}
+
+// statusModel converts the daemon's status for a status line template.
+func statusModel(status *daemon.StatusData) statusline.Status {
+	state := statusline.State(status.TimerState, status.Phase)
+	iconState := state
+	if state == "idle" {
+		// An idle timer keeps the icon of the phase it will start, as in
+		// the daemon's status line and the menu bar.
+		iconState = statusline.State("running", status.Phase)
+	}
+	s := statusline.Status{
+		State:            state,
+		Phase:            status.Phase,
+		Icon:             statusline.Icon(iconState),
+		ASCIIIcon:        statusline.ASCIIIcon(iconState),
+		Remaining:        status.Remaining,
+		RemainingSeconds: status.RemainingSeconds,
+		Today:            status.IntervalsToday,
+		Goal:             status.DailyGoal,
+		DayOff:           status.DayOff,
+		Sparkline:        status.Sparkline,
+		Week:             status.WeekValues,
+		Streak:           status.Streak,
+		Interruptions:    status.Interruptions,
+	}
+	if status.CurrentTask != nil {
+		s.Task = status.CurrentTask.Title
+	}
+
+	if s.DayOff && s.Goal == 0 {
+		s.Progress = fmt.Sprintf("%d/off", s.Today)
+	} else {
+		s.Progress = fmt.Sprintf("%d/%d", s.Today, s.Goal)
+	}
+	if s.Goal > 0 {
+		s.Percent = min(s.Today*100/s.Goal, 100)
+		s.GoalMet = s.Today >= s.Goal
+	}
+	return s
+}
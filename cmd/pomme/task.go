@@ -0,0 +1,177 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/philleif/pomme/internal/client"
+	"github.com/philleif/pomme/internal/storage"
+	"github.com/philleif/pomme/internal/warrior"
+)
+
+func runTask(args []string) {
+	if len(args) == 0 {
+		taskUsage()
+		os.Exit(2)
+	}
+
+	c := client.New()
+	ensureDaemon(c, true)
+
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("task add", flag.ExitOnError)
+		project := fs.String("project", "", "Project name")
+		estimate := fs.Int("estimate", 0, "Estimated pomodoros")
+		selectTask := fs.Bool("select", false, "Make it the current task")
+		rest, err := parseInterspersed(fs, args[1:])
+		if err != nil || len(rest) == 0 {
+			taskUsage()
+			os.Exit(2)
+		}
+		task, err := c.AddTask(strings.Join(rest, " "), *project, *estimate, *selectTask)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Added task %d: %s\n", task.ID, task.Title)
+		if *selectTask {
+			fmt.Println("Now the current task")
+		}
+
+	case "list", "ls":
+		fs := flag.NewFlagSet("task list", flag.ExitOnError)
+		all := fs.Bool("all", false, "Include finished tasks")
+		fs.Parse(args[1:])
+		tasks, err := c.Tasks(*all)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		status, _ := c.Status()
+		var currentID int64
+		if status != nil && status.CurrentTask != nil {
+			currentID = status.CurrentTask.ID
+		}
+		if len(tasks) == 0 {
+			fmt.Println("No tasks. Add one with: pomme task add <title>")
+			return
+		}
+		for _, t := range tasks {
+			fmt.Println(formatTaskLine(t, t.ID == currentID))
+		}
+
+	case "done":
+		var id int64
+		if len(args) > 1 {
+			id = parseTaskID(args[1])
+		}
+		task, err := c.CompleteTask(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Done: %s (%d pomodoros)\n", task.Title, task.Completed)
+
+	case "select":
+		if len(args) != 2 {
+			taskUsage()
+			os.Exit(2)
+		}
+		var id int64
+		if args[1] != "none" {
+			id = parseTaskID(args[1])
+		}
+		status, err := c.SelectTask(id)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if status.CurrentTask == nil {
+			fmt.Println("No current task")
+		} else {
+			fmt.Printf("Current task: %s\n", status.CurrentTask.Title)
+		}
+
+	case "pick":
+		if len(args) == 1 {
+			listTaskwarrior()
+			return
+		}
+		if len(args) != 2 {
+			taskUsage()
+			os.Exit(2)
+		}
+		status, err := c.PickTask(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Current task: %s\n", status.CurrentTask.Title)
+
+	default:
+		taskUsage()
+		os.Exit(2)
+	}
+}
+
+// listTaskwarrior prints pending Taskwarrior tasks to pick from.
+func listTaskwarrior() {
+	tasks, err := warrior.NewTaskwarrior().Pending()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(tasks) == 0 {
+		fmt.Println("No pending Taskwarrior tasks")
+		return
+	}
+	for _, t := range tasks {
+		line := fmt.Sprintf("%4d  %5.1f  %s", t.ID, t.Urgency, t.Description)
+		if t.Project != "" {
+			line += "  [" + t.Project + "]"
+		}
+		fmt.Println(line)
+	}
+	fmt.Println("Pick one with: pomme task pick <id>")
+}
+
+func taskUsage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  pomme task add <title> [--project name] [--estimate n] [--select]")
+	fmt.Fprintln(os.Stderr, "  pomme task list [--all]")
+	fmt.Fprintln(os.Stderr, "  pomme task done [id]        (defaults to the current task)")
+	fmt.Fprintln(os.Stderr, "  pomme task select <id|none>")
+	fmt.Fprintln(os.Stderr, "  pomme task pick [taskwarrior id|uuid]   (lists pending tasks without one)")
+}
+
+func parseTaskID(s string) int64 {
+	id, err := strconv.ParseInt(strings.TrimPrefix(s, "#"), 10, 64)
+	if err != nil || id <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: invalid task id %q\n", s)
+		os.Exit(2)
+	}
+	return id
+}
+
+func formatTaskLine(t storage.Task, current bool) string {
+	marker := " "
+	if current {
+		marker = "▶"
+	}
+	progress := strconv.Itoa(t.Completed)
+	if t.Estimate > 0 {
+		progress = fmt.Sprintf("%d/%d", t.Completed, t.Estimate)
+	}
+	line := fmt.Sprintf("%s %3d  %-5s %s", marker, t.ID, progress, t.Title)
+	if t.Project != "" {
+		line += "  [" + t.Project + "]"
+	}
+	if t.Status == storage.TaskDone {
+		line += "  (done)"
+	}
+	return line
+}
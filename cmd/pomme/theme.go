@@ -0,0 +1,22 @@
+package main
+
+import (
+	"github.com/philleif/pomme/internal/config"
+	"github.com/philleif/pomme/internal/theme"
+)
+
+// clientConfig is the config for the keys this process reads itself (see
+// clientKeys): the config file, environment and this command's flags, not
+// whatever the daemon started with.
+func clientConfig() config.Config {
+	resolved, err := config.Resolve(configOverrides)
+	exitOnError(err)
+	return resolved.Config
+}
+
+// loadTheme loads the configured theme.
+func loadTheme(cfg config.Config) theme.Theme {
+	t, err := theme.Load(cfg.Theme, cfg.Themes, cfg.ThemeColors)
+	exitOnError(err)
+	return t
+}
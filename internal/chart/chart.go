@@ -0,0 +1,229 @@
+// Package chart draws pomodoro charts as SVG or PNG for embedding in pages
+// and posts: daily bars with each day's goal, or a weekday × hour heatmap.
+//
+// A chart is laid out once as rectangles and text, then written by a
+// renderer. SVG leaves the font to the viewer; PNG draws text in a fixed
+// bitmap font.
+package chart
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+
+	"github.com/philleif/pomme/internal/sparkline"
+)
+
+type Format string
+
+const (
+	FormatSVG Format = "svg"
+	FormatPNG Format = "png"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatSVG, FormatPNG:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown chart format %q (use svg or png)", s)
+}
+
+// Bar is one bar of a bar chart. Goal draws a marker at that height; zero
+// draws none.
+type Bar struct {
+	Label string
+	Value int
+	Goal  int
+}
+
+// Chart is what to draw: Bars, or a heatmap of Heat rows when Bars is empty.
+type Chart struct {
+	Title string
+	Bars  []Bar
+	// Scale is what the top of the bars stands for, as in the terminal
+	// graphs; empty is sparkline.ScaleAuto.
+	Scale sparkline.Scale
+
+	Heat      [][]int
+	RowLabels []string
+	ColLabels []string // every third is drawn
+}
+
+// Theme is the palette of a chart; the theme package makes one from a
+// pomme theme. Colors with zero alpha, such as a transparent Background,
+// are not drawn.
+type Theme struct {
+	Background color.NRGBA
+	Text       color.NRGBA
+	Muted      color.NRGBA // axis labels
+	Grid       color.NRGBA
+	Bar        color.NRGBA
+	BarGoalMet color.NRGBA
+	Overflow   color.NRGBA // cap on bars cut off by sparkline.ScaleGoal
+	Goal       color.NRGBA
+	Empty      color.NRGBA // heatmap cell with no pomodoros
+	HeatLow    color.NRGBA
+	Heat       color.NRGBA // busiest heatmap cell
+}
+
+// Options sizes and colors a chart.
+type Options struct {
+	Width  int
+	Height int
+	Theme  Theme
+}
+
+func (o Options) Validate() error {
+	if o.Width < 64 || o.Height < 48 {
+		return fmt.Errorf("chart too small: %dx%d (minimum 64x48)", o.Width, o.Height)
+	}
+	return nil
+}
+
+// Write renders c to w in format.
+func Write(w io.Writer, c Chart, opts Options, format Format) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	shapes := layout(c, opts)
+	switch format {
+	case FormatSVG:
+		return writeSVG(w, shapes, opts)
+	case FormatPNG:
+		return writePNG(w, shapes, opts)
+	}
+	return fmt.Errorf("unknown chart format %q", format)
+}
+
+// shape is a filled rectangle or, with text set, a label whose x is its
+// anchor point and y its baseline.
+type shape struct {
+	x, y, w, h int
+	fill       color.NRGBA
+	text       string
+	anchor     string // start, middle or end
+	size       int
+}
+
+const (
+	padding   = 12
+	titleSize = 14
+	labelSize = 11
+)
+
+func layout(c Chart, opts Options) []shape {
+	t := opts.Theme
+	shapes := []shape{{x: 0, y: 0, w: opts.Width, h: opts.Height, fill: t.Background}}
+
+	top := padding
+	if c.Title != "" {
+		shapes = append(shapes, shape{x: padding, y: padding + titleSize, fill: t.Text, text: c.Title, anchor: "start", size: titleSize})
+		top += titleSize + padding
+	}
+
+	if len(c.Bars) > 0 {
+		return append(shapes, layoutBars(c.Bars, c.Scale, t, padding, top, opts.Width-2*padding, opts.Height-top-padding)...)
+	}
+	return append(shapes, layoutHeat(c, t, padding, top, opts.Width-2*padding, opts.Height-top-padding)...)
+}
+
+// layoutBars fills the area x, y, w, h with bars laid out as in the
+// terminal graphs, labels below and values above. Bars cut off by the scale
+// get an overflow cap.
+func layoutBars(bars []Bar, scale sparkline.Scale, t Theme, x, y, w, h int) []shape {
+	var shapes []shape
+	values := make([]int, len(bars))
+	goals := make([]int, len(bars))
+	for i, b := range bars {
+		values[i], goals[i] = b.Value, b.Goal
+	}
+
+	// Room for the value above the tallest bar and a label row below.
+	plotTop := y + labelSize + 4
+	baseline := y + h - labelSize - 6
+	plotH := max(baseline-plotTop, 1)
+
+	slot := w / len(bars)
+	barW := max(slot*7/10, 1)
+	shapes = append(shapes, shape{x: x, y: baseline, w: w, h: 1, fill: t.Grid})
+
+	for i, l := range sparkline.LayoutBars(values, goals, scale, plotH, 1) {
+		b := bars[i]
+		cx := x + i*slot + slot/2
+		fill := t.Bar
+		if l.GoalMet {
+			fill = t.BarGoalMet
+		}
+		if l.Height > 0 {
+			shapes = append(shapes, shape{x: cx - barW/2, y: baseline - l.Height, w: barW, h: l.Height, fill: fill})
+		}
+		if l.Overflow {
+			shapes = append(shapes, shape{x: cx - barW/2, y: baseline - l.Height, w: barW, h: 3, fill: t.Overflow})
+		}
+		if l.HasGoal {
+			shapes = append(shapes, shape{x: cx - slot/2 + 1, y: baseline - l.Goal - 1, w: slot - 2, h: 2, fill: t.Goal})
+		}
+		if b.Value > 0 {
+			shapes = append(shapes, shape{x: cx, y: baseline - l.Height - 4, fill: t.Text, text: fmt.Sprint(b.Value), anchor: "middle", size: labelSize})
+		}
+		shapes = append(shapes, shape{x: cx, y: y + h, fill: t.Muted, text: b.Label, anchor: "middle", size: labelSize})
+	}
+	return shapes
+}
+
+// layoutHeat fills the area x, y, w, h with a grid of cells shaded from
+// Empty to Heat by their share of the largest count.
+func layoutHeat(c Chart, t Theme, x, y, w, h int) []shape {
+	var shapes []shape
+	if len(c.Heat) == 0 || len(c.Heat[0]) == 0 {
+		return shapes
+	}
+	most := 0
+	for _, row := range c.Heat {
+		for _, v := range row {
+			most = max(most, v)
+		}
+	}
+
+	labelW := 0
+	if len(c.RowLabels) > 0 {
+		labelW = 32
+	}
+	gridTop := y
+	if len(c.ColLabels) > 0 {
+		gridTop += labelSize + 4
+	}
+	cols, rows := len(c.Heat[0]), len(c.Heat)
+	cellW := max((w-labelW)/cols, 1)
+	cellH := max((y+h-gridTop)/rows, 1)
+	gap := 1
+	if cellW > 8 && cellH > 8 {
+		gap = 2
+	}
+
+	for col := 0; col < len(c.ColLabels) && col < cols; col += 3 {
+		shapes = append(shapes, shape{x: x + labelW + col*cellW, y: y + labelSize, fill: t.Muted, text: c.ColLabels[col], anchor: "start", size: labelSize})
+	}
+	for r, row := range c.Heat {
+		cy := gridTop + r*cellH
+		if r < len(c.RowLabels) {
+			shapes = append(shapes, shape{x: x, y: cy + cellH/2 + labelSize/2 - 1, fill: t.Muted, text: c.RowLabels[r], anchor: "start", size: labelSize})
+		}
+		for col, v := range row {
+			fill := t.Empty
+			if v > 0 && most > 0 {
+				fill = mix(t.HeatLow, t.Heat, float64(v)/float64(most))
+			}
+			shapes = append(shapes, shape{x: x + labelW + col*cellW, y: cy, w: cellW - gap, h: cellH - gap, fill: fill})
+		}
+	}
+	return shapes
+}
+
+// mix blends a towards b by f (0 to 1).
+func mix(a, b color.NRGBA, f float64) color.NRGBA {
+	blend := func(x, y uint8) uint8 { return uint8(float64(x) + f*(float64(y)-float64(x)) + 0.5) }
+	return color.NRGBA{blend(a.R, b.R), blend(a.G, b.G), blend(a.B, b.B), blend(a.A, b.A)}
+}
@@ -0,0 +1,244 @@
+package chart
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/philleif/pomme/internal/sparkline"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var testTheme = Theme{
+	Background: color.NRGBA{255, 255, 255, 255},
+	Text:       color.NRGBA{31, 35, 40, 255},
+	Muted:      color.NRGBA{110, 119, 129, 255},
+	Grid:       color.NRGBA{140, 149, 159, 255},
+	Bar:        color.NRGBA{244, 169, 155, 255},
+	BarGoalMet: color.NRGBA{217, 56, 30, 255},
+	Overflow:   color.NRGBA{191, 135, 0, 255},
+	Goal:       color.NRGBA{87, 96, 106, 255},
+	Empty:      color.NRGBA{235, 237, 240, 255},
+	HeatLow:    color.NRGBA{251, 211, 203, 255},
+	Heat:       color.NRGBA{217, 56, 30, 255},
+}
+
+func weekFixture() Chart {
+	return Chart{
+		Title: "Last 7 days: 41 pomodoros",
+		Bars: []Bar{
+			{Label: "Mon", Value: 8, Goal: 8},
+			{Label: "Tue", Value: 5, Goal: 8},
+			{Label: "Wed", Value: 12, Goal: 8},
+			{Label: "Thu", Value: 0, Goal: 8},
+			{Label: "Fri", Value: 7, Goal: 6},
+			{Label: "Sat", Value: 3},
+			{Label: "Sun", Value: 6, Goal: 4},
+		},
+	}
+}
+
+func heatmapFixture() Chart {
+	heat := make([][]int, 7)
+	for day := range heat {
+		heat[day] = make([]int, 24)
+		for hour := 8; hour < 18; hour++ {
+			heat[day][hour] = (day*3 + hour) % 7
+		}
+	}
+	hours := make([]string, 24)
+	for h := range hours {
+		hours[h] = string(rune('0'+h/10)) + string(rune('0'+h%10))
+	}
+	return Chart{
+		Title:     "Pomodoros by start time",
+		Heat:      heat,
+		RowLabels: []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
+		ColLabels: hours,
+	}
+}
+
+var goldenCharts = []struct {
+	name  string
+	chart Chart
+}{
+	{"week", weekFixture()},
+	{"heatmap", heatmapFixture()},
+}
+
+func TestSVGGolden(t *testing.T) {
+	for _, tt := range goldenCharts {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.chart, Options{Width: 640, Height: 240, Theme: testTheme}, FormatSVG); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join("testdata", tt.name+".svg")
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("%s differs from the chart drawn now (rerun with -update if intended):\n%s", path, buf.String())
+			}
+		})
+	}
+}
+
+// PNG goldens are compared by pixel, as the encoder's compression may
+// change between Go releases.
+func TestPNGGolden(t *testing.T) {
+	for _, tt := range goldenCharts {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.chart, Options{Width: 640, Height: 240, Theme: testTheme}, FormatPNG); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join("testdata", tt.name+".png")
+			if *update {
+				if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := png.Decode(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			want, err := png.Decode(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Bounds() != want.Bounds() {
+				t.Fatalf("size %v, want %v", got.Bounds(), want.Bounds())
+			}
+			for y := got.Bounds().Min.Y; y < got.Bounds().Max.Y; y++ {
+				for x := got.Bounds().Min.X; x < got.Bounds().Max.X; x++ {
+					if !sameColor(got.At(x, y), want.At(x, y)) {
+						t.Fatalf("%s: pixel (%d, %d) is %v, want %v (rerun with -update if intended)", path, x, y, got.At(x, y), want.At(x, y))
+					}
+				}
+			}
+		})
+	}
+}
+
+func sameColor(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
+
+// The PNG carries the same text as the SVG: the title is drawn in the
+// title's color, and labels in the muted color.
+func TestRasterText(t *testing.T) {
+	c := weekFixture()
+	img := Raster(c, Options{Width: 640, Height: 240, Theme: testTheme})
+
+	count := func(r image.Rectangle, want color.NRGBA) int {
+		n := 0
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				if sameColor(img.At(x, y), want) {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	// The title's left part, clear of the tallest bar's value.
+	title := image.Rect(0, 0, 180, padding+titleSize+4)
+	if n := count(title, testTheme.Text); n == 0 {
+		t.Error("no title drawn")
+	}
+	if n := count(image.Rect(0, 240-padding-labelSize, 640, 240), testTheme.Muted); n == 0 {
+		t.Error("no day labels drawn")
+	}
+
+	c.Title = ""
+	img = Raster(c, Options{Width: 640, Height: 240, Theme: testTheme})
+	if n := count(title, testTheme.Text); n != 0 {
+		t.Errorf("%d title pixels drawn without a title", n)
+	}
+}
+
+// Bars follow the terminal graph's layout: with the goal scale, a bar over
+// the largest goal is cut off at the top and capped, and the goal line sits
+// at the top of the plot.
+func TestBarsShareLayout(t *testing.T) {
+	c := Chart{Scale: sparkline.ScaleGoal, Bars: []Bar{
+		{Label: "Mon", Value: 4, Goal: 8},
+		{Label: "Tue", Value: 12, Goal: 8},
+	}}
+	shapes := layout(c, Options{Width: 200, Height: 120, Theme: testTheme})
+
+	var bars, caps, goals []shape
+	for _, sh := range shapes {
+		switch {
+		case sh.text != "":
+		case sh.fill == testTheme.Bar || sh.fill == testTheme.BarGoalMet:
+			bars = append(bars, sh)
+		case sh.fill == testTheme.Overflow:
+			caps = append(caps, sh)
+		case sh.fill == testTheme.Goal:
+			goals = append(goals, sh)
+		}
+	}
+	if len(bars) != 2 || len(caps) != 1 || len(goals) != 2 {
+		t.Fatalf("got %d bars, %d caps and %d goal lines, want 2, 1 and 2", len(bars), len(caps), len(goals))
+	}
+
+	// 120 pixels less padding, the value row above and the label row below.
+	const plotH = 64
+	baseline := bars[0].y + bars[0].h
+	want := sparkline.LayoutBars([]int{4, 12}, []int{8, 8}, sparkline.ScaleGoal, plotH, 1)
+	if bars[0].h != want[0].Height || bars[1].h != plotH {
+		t.Errorf("bar heights %d and %d, want %d and a cut-off bar of %d", bars[0].h, bars[1].h, want[0].Height, plotH)
+	}
+	if caps[0].x != bars[1].x || caps[0].y != bars[1].y {
+		t.Errorf("cap at (%d, %d), want on top of the cut-off bar at (%d, %d)", caps[0].x, caps[0].y, bars[1].x, bars[1].y)
+	}
+	if goalY := goals[0].y + 1; goalY != baseline-plotH {
+		t.Errorf("goal line at y %d, want the top of the plot at %d", goalY, baseline-plotH)
+	}
+}
+
+func TestTransparentBackground(t *testing.T) {
+	theme := testTheme
+	theme.Background = color.NRGBA{}
+	var buf bytes.Buffer
+	if err := Write(&buf, weekFixture(), Options{Width: 640, Height: 240, Theme: theme}, FormatSVG); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`width="640" height="240" fill`)) {
+		t.Error("transparent chart has a background rectangle")
+	}
+	img := Raster(weekFixture(), Options{Width: 640, Height: 240, Theme: theme})
+	if _, _, _, a := img.At(1, 1).RGBA(); a != 0 {
+		t.Errorf("corner alpha = %d, want 0", a)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	if err := (Options{Width: 63, Height: 240}).Validate(); err == nil {
+		t.Error("63 pixels wide accepted")
+	}
+	if err := (Options{Width: 64, Height: 48}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,91 @@
+package chart
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+func writeSVG(w io.Writer, shapes []shape, opts Options) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="-apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif">`+"\n",
+		opts.Width, opts.Height, opts.Width, opts.Height)
+	for _, s := range shapes {
+		if s.fill.A == 0 {
+			continue
+		}
+		if s.text != "" {
+			var text strings.Builder
+			xml.EscapeText(&text, []byte(s.text))
+			fmt.Fprintf(b, `  <text x="%d" y="%d" font-size="%d" text-anchor="%s" fill="%s">%s</text>`+"\n",
+				s.x, s.y, s.size, s.anchor, svgColor(s.fill), text.String())
+			continue
+		}
+		fmt.Fprintf(b, `  <rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			s.x, s.y, s.w, s.h, svgColor(s.fill))
+	}
+	b.WriteString("</svg>\n")
+	return b.Flush()
+}
+
+// svgColor writes c as #rrggbb, with an rgba() fallback when translucent.
+func svgColor(c color.NRGBA) string {
+	if c.A == 255 {
+		return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("rgba(%d,%d,%d,%.3g)", c.R, c.G, c.B, float64(c.A)/255)
+}
+
+// Raster draws c into an RGBA image.
+func Raster(c Chart, opts Options) *image.RGBA {
+	return raster(layout(c, opts), opts)
+}
+
+// rasterFace is the font of PNG charts. It is a 7×13 bitmap, so text comes
+// out the same size whatever the shape asks for.
+var rasterFace = basicfont.Face7x13
+
+func raster(shapes []shape, opts Options) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))
+	for _, s := range shapes {
+		if s.fill.A == 0 {
+			continue
+		}
+		if s.text != "" {
+			drawText(img, s)
+			continue
+		}
+		r := image.Rect(s.x, s.y, s.x+s.w, s.y+s.h)
+		draw.Draw(img, r, &image.Uniform{s.fill}, image.Point{}, draw.Over)
+	}
+	return img
+}
+
+// drawText draws a text shape with its baseline at s.y, placed against s.x
+// by its anchor as SVG's text-anchor would.
+func drawText(img draw.Image, s shape) {
+	d := font.Drawer{Dst: img, Src: &image.Uniform{s.fill}, Face: rasterFace}
+	x := fixed.I(s.x)
+	switch s.anchor {
+	case "middle":
+		x -= d.MeasureString(s.text) / 2
+	case "end":
+		x -= d.MeasureString(s.text)
+	}
+	d.Dot = fixed.Point26_6{X: x, Y: fixed.I(s.y)}
+	d.DrawString(s.text)
+}
+
+func writePNG(w io.Writer, shapes []shape, opts Options) error {
+	return png.Encode(w, raster(shapes, opts))
+}
@@ -7,28 +7,39 @@ import (
 	"net"
 	"time"
 
+	"github.com/philleif/pomme/internal/config"
 	"github.com/philleif/pomme/internal/daemon"
-	"github.com/philleif/pomme/internal/storage"
+	"github.com/philleif/pomme/internal/paths"
 )
 
 type Client struct {
 	socketPath string
+	pathErr    error
 }
 
 func New() *Client {
+	socketPath, err := paths.SocketPath()
 	return &Client{
-		socketPath: storage.SocketPath(),
+		socketPath: socketPath,
+		pathErr:    err,
 	}
 }
 
 func (c *Client) sendCommand(action string) (*daemon.Response, error) {
+	return c.send(daemon.Command{Action: action})
+}
+
+func (c *Client) send(cmd daemon.Command) (*daemon.Response, error) {
+	if c.pathErr != nil {
+		return nil, c.pathErr
+	}
+
 	conn, err := net.DialTimeout("unix", c.socketPath, 2*time.Second)
 	if err != nil {
 		return nil, fmt.Errorf("daemon not running (start with 'pomme --daemon')")
 	}
 	defer conn.Close()
 
-	cmd := daemon.Command{Action: action}
 	data, _ := json.Marshal(cmd)
 	conn.Write(append(data, '\n'))
 
@@ -165,7 +176,64 @@ func (c *Client) ToggleAlways() (*daemon.StatusData, error) {
 	return &status, nil
 }
 
+func (c *Client) ReloadConfig() (*daemon.StatusData, error) {
+	resp, err := c.sendCommand("reload_config")
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var status daemon.StatusData
+	json.Unmarshal(data, &status)
+
+	return &status, nil
+}
+
+// InvalidateStats tells the daemon the history changed behind its back, so
+// it recomputes streaks and today's count.
+func (c *Client) InvalidateStats() (*daemon.StatusData, error) {
+	resp, err := c.sendCommand("invalidate_stats")
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var status daemon.StatusData
+	json.Unmarshal(data, &status)
+
+	return &status, nil
+}
+
+// Config returns the daemon's effective config and where each value came from.
+func (c *Client) Config() (*config.Resolved, error) {
+	resp, err := c.sendCommand("config")
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var resolved config.Resolved
+	json.Unmarshal(data, &resolved)
+
+	return &resolved, nil
+}
+
 func (c *Client) IsRunning() bool {
+	if c.pathErr != nil {
+		return false
+	}
 	conn, err := net.DialTimeout("unix", c.socketPath, 500*time.Millisecond)
 	if err != nil {
 		return false
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/philleif/pomme/internal/daemon"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+// Note attaches a note and tags to the running work interval, or to the
+// last completed one.
+func (c *Client) Note(note string, tags []string) (*daemon.NoteData, error) {
+	resp, err := c.send(daemon.Command{
+		Action: "note",
+		Params: map[string]string{
+			"note": note,
+			"tags": strings.Join(tags, ","),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var result daemon.NoteData
+	json.Unmarshal(data, &result)
+
+	return &result, nil
+}
+
+// Stats returns weekly stats, limited to intervals tagged with tag unless it
+// is empty.
+func (c *Client) Stats(tag string) (*daemon.StatsData, error) {
+	resp, err := c.send(daemon.Command{
+		Action: "stats",
+		Params: map[string]string{"tag": tag},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var stats daemon.StatsData
+	json.Unmarshal(data, &stats)
+
+	return &stats, nil
+}
+
+// Interrupt logs an "internal" or "external" interruption of the running
+// work interval.
+func (c *Client) Interrupt(kind, note string) (*storage.Interruption, error) {
+	resp, err := c.send(daemon.Command{
+		Action: "interrupt",
+		Params: map[string]string{"kind": kind, "note": note},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var interruption storage.Interruption
+	json.Unmarshal(data, &interruption)
+
+	return &interruption, nil
+}
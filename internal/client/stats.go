@@ -0,0 +1,76 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/philleif/pomme/internal/daemon"
+	"github.com/philleif/pomme/internal/sparkline"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+// StatsQuery runs a grouped statistics query on the daemon.
+func (c *Client) StatsQuery(q storage.StatsQuery) ([]storage.Bucket, error) {
+	params := map[string]string{
+		"by":   string(q.GroupBy),
+		"from": q.From,
+		"to":   q.To,
+		"tag":  q.Tag,
+		"fill": strconv.FormatBool(q.Fill),
+	}
+	if q.TaskID != 0 {
+		params["task"] = strconv.FormatInt(q.TaskID, 10)
+	}
+	resp, err := c.send(daemon.Command{Action: "stats_query", Params: params})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var buckets []storage.Bucket
+	json.Unmarshal(data, &buckets)
+
+	return buckets, nil
+}
+
+// Heatmap returns work intervals by weekday and hour between two dates
+// (YYYY-MM-DD, inclusive; empty means unbounded).
+func (c *Client) Heatmap(from, to string) (*storage.Heatmap, error) {
+	resp, err := c.send(daemon.Command{Action: "heatmap", Params: map[string]string{"from": from, "to": to}})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var heatmap storage.Heatmap
+	json.Unmarshal(data, &heatmap)
+
+	return &heatmap, nil
+}
+
+// Calendar returns the days of the last weeks ISO weeks, Monday first.
+func (c *Client) Calendar(weeks int) ([]sparkline.CalendarDay, error) {
+	resp, err := c.send(daemon.Command{Action: "calendar", Params: map[string]string{"weeks": strconv.Itoa(weeks)}})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var days []sparkline.CalendarDay
+	json.Unmarshal(data, &days)
+
+	return days, nil
+}
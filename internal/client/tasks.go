@@ -0,0 +1,119 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/philleif/pomme/internal/daemon"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func (c *Client) AddTask(title, project string, estimate int, selectTask bool) (*storage.Task, error) {
+	resp, err := c.send(daemon.Command{
+		Action: "task_add",
+		Params: map[string]string{
+			"title":    title,
+			"project":  project,
+			"estimate": strconv.Itoa(estimate),
+			"select":   strconv.FormatBool(selectTask),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var task storage.Task
+	json.Unmarshal(data, &task)
+
+	return &task, nil
+}
+
+func (c *Client) Tasks(all bool) ([]storage.Task, error) {
+	resp, err := c.send(daemon.Command{
+		Action: "task_list",
+		Params: map[string]string{"all": strconv.FormatBool(all)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var tasks []storage.Task
+	json.Unmarshal(data, &tasks)
+
+	return tasks, nil
+}
+
+// CompleteTask marks a task done. An id of 0 means the current task.
+func (c *Client) CompleteTask(id int64) (*storage.Task, error) {
+	params := map[string]string{}
+	if id != 0 {
+		params["id"] = strconv.FormatInt(id, 10)
+	}
+	resp, err := c.send(daemon.Command{Action: "task_done", Params: params})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var task storage.Task
+	json.Unmarshal(data, &task)
+
+	return &task, nil
+}
+
+// SelectTask sets the daemon's current task. An id of 0 clears it.
+func (c *Client) SelectTask(id int64) (*daemon.StatusData, error) {
+	resp, err := c.send(daemon.Command{
+		Action: "task_select",
+		Params: map[string]string{"id": strconv.FormatInt(id, 10)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var status daemon.StatusData
+	json.Unmarshal(data, &status)
+
+	return &status, nil
+}
+
+// PickTask makes a Taskwarrior task, by ID or UUID, the daemon's current task.
+func (c *Client) PickTask(ref string) (*daemon.StatusData, error) {
+	resp, err := c.send(daemon.Command{
+		Action: "task_pick",
+		Params: map[string]string{"ref": ref},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf(resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var status daemon.StatusData
+	json.Unmarshal(data, &status)
+
+	return &status, nil
+}
@@ -5,26 +5,46 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/philleif/pomme/internal/paths"
+	"github.com/philleif/pomme/internal/theme"
 )
 
 type Config struct {
-	WorkDuration       int  `json:"work_duration_minutes"`
-	ShortBreakDuration int  `json:"short_break_duration_minutes"`
-	LongBreakDuration  int  `json:"long_break_duration_minutes"`
-	LongBreakAfter     int  `json:"long_break_after_intervals"`
-	DailyGoal          int  `json:"daily_goal"`
-	BlockMessages      bool `json:"block_messages_enabled"`
-	AlwaysBlock        bool `json:"always_block"`
-	SimpleBarEnabled   bool `json:"simplebar_enabled"`
-	SimpleBarWidgetID  int  `json:"simplebar_widget_id"`
-	SimpleBarPort      int  `json:"simplebar_port"`
+	WorkDuration       Duration      `json:"work_duration"`
+	ShortBreakDuration Duration      `json:"short_break_duration"`
+	LongBreakDuration  Duration      `json:"long_break_duration"`
+	LongBreakAfter     int           `json:"long_break_after_intervals"`
+	DailyGoal          int           `json:"daily_goal"`
+	BlockMessages      bool          `json:"block_messages_enabled"`
+	AlwaysBlock        bool          `json:"always_block"`
+	SimpleBarEnabled   bool          `json:"simplebar_enabled"`
+	SimpleBarWidgetID  int           `json:"simplebar_widget_id"`
+	SimpleBarPort      int           `json:"simplebar_port"`
+	ICSPort            int           `json:"ics_port"`
+	TimewarriorEnabled bool          `json:"timewarrior_enabled"`
+	JournalFile        string        `json:"journal_file"`
+	JournalTime        string        `json:"journal_time"`
+	JournalFormat      string        `json:"journal_format"`
+	JournalTemplate    string        `json:"journal_template"`
+	RestDays           Weekdays      `json:"rest_days"`
+	WeekdayGoals       WeekdayGoals  `json:"weekday_goals"`
+	GoalOverrides      GoalOverrides `json:"goal_overrides"`
+	ChartWidth         int           `json:"chart_width"`
+	ChartHeight        int           `json:"chart_height"`
+	GraphRenderer      string        `json:"graph_renderer"`
+	GraphScale         string        `json:"graph_scale"`
+	GraphPixelRatio    int           `json:"graph_pixel_ratio"`
+	Theme              string        `json:"theme"`
+	ThemeColors        string        `json:"theme_colors"`
+	Themes             theme.Customs `json:"themes"`
 }
 
 func Default() Config {
 	return Config{
-		WorkDuration:       30,
-		ShortBreakDuration: 5,
-		LongBreakDuration:  20,
+		WorkDuration:       Duration(30 * time.Minute),
+		ShortBreakDuration: Duration(5 * time.Minute),
+		LongBreakDuration:  Duration(20 * time.Minute),
 		LongBreakAfter:     4,
 		DailyGoal:          12,
 		BlockMessages:      true,
@@ -32,52 +52,72 @@ func Default() Config {
 		SimpleBarEnabled:   false,
 		SimpleBarWidgetID:  1,
 		SimpleBarPort:      7776,
+		ICSPort:            0,
+		TimewarriorEnabled: false,
+		JournalFile:        "",
+		JournalTime:        "21:00",
+		JournalFormat:      "md",
+		JournalTemplate:    "",
+		RestDays:           Weekdays{},
+		WeekdayGoals:       WeekdayGoals{},
+		GoalOverrides:      GoalOverrides{},
+		ChartWidth:         640,
+		ChartHeight:        240,
+		GraphRenderer:      "auto",
+		GraphScale:         "auto",
+		GraphPixelRatio:    1,
+		Theme:              "dark",
+		ThemeColors:        "",
+		Themes:             theme.Customs{},
 	}
 }
 
-func configDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	dir := filepath.Join(home, ".pomme")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", err
-	}
-	return dir, nil
-}
-
 func ConfigPath() string {
-	dir, err := configDir()
+	dir, err := paths.ConfigDir()
 	if err != nil {
 		return ""
 	}
 	return filepath.Join(dir, "config.json")
 }
 
+// Load reads the config file only. Use Resolve to also apply environment
+// and flag overrides.
 func Load() (Config, error) {
+	cfg, _, err := loadFile(true)
+	return cfg, err
+}
+
+// loadFile reads the config file, writing the defaults there first when
+// create is set and there is none.
+func loadFile(create bool) (Config, map[string]bool, error) {
 	cfg := Default()
 
 	path := ConfigPath()
 	if path == "" {
-		return cfg, nil
+		return cfg, nil, nil
 	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Create default config file
-			Save(cfg)
-			return cfg, nil
+			if create {
+				Save(cfg)
+			}
+			return cfg, nil, nil
 		}
-		return cfg, err
+		return cfg, nil, err
 	}
 
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return Default(), err
+	cfg, set, err := parse(data)
+	if err != nil {
+		if verr, ok := err.(*ValidationError); ok {
+			verr.Path = path
+		}
+		return Default(), nil, err
 	}
 
-	return cfg, nil
+	return cfg, set, nil
 }
 
 func Save(cfg Config) error {
@@ -95,13 +135,13 @@ func Save(cfg Config) error {
 }
 
 func (c Config) WorkDurationTime() time.Duration {
-	return time.Duration(c.WorkDuration) * time.Minute
+	return time.Duration(c.WorkDuration)
 }
 
 func (c Config) ShortBreakDurationTime() time.Duration {
-	return time.Duration(c.ShortBreakDuration) * time.Minute
+	return time.Duration(c.ShortBreakDuration)
 }
 
 func (c Config) LongBreakDurationTime() time.Duration {
-	return time.Duration(c.LongBreakDuration) * time.Minute
+	return time.Duration(c.LongBreakDuration)
 }
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/philleif/pomme/internal/paths"
+)
+
+const requiredJSON = `"work_duration": "25m", "short_break_duration": "5m", "long_break_duration": "20m",
+	"long_break_after_intervals": 4, "daily_goal": 8`
+
+func TestThemes(t *testing.T) {
+	data := `{` + requiredJSON + `,
+		"theme": "mine",
+		"themes": {"mine": {"base": "light", "accent": "#8250df"}}}`
+	cfg, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.Themes["mine"]; got.Base != "light" || got.Colors["accent"] != "#8250df" {
+		t.Errorf("themes.mine = %+v", got)
+	}
+	if got, _ := cfg.Get("themes"); got != `{"mine":{"accent":"#8250df","base":"light"}}` {
+		t.Errorf("Get(themes) = %s", got)
+	}
+
+	cfg = Default()
+	if err := cfg.Set("themes", `{"night": {"bar": "#333333"}}`); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Set("theme", "night"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("custom theme rejected: %v", err)
+	}
+
+	// Theme names ignore case, as theme.Load does.
+	for _, name := range []string{"Night", "LIGHT"} {
+		cfg.Theme = name
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("theme %q rejected: %v", name, err)
+		}
+	}
+	if slices.Contains(FlagKeys(), "themes") {
+		t.Error("FlagKeys includes themes")
+	}
+}
+
+func TestThemesInvalid(t *testing.T) {
+	tests := []struct {
+		json, key, msg string
+	}{
+		{`"theme": "mine"`, "theme", `must be one of colorblind, dark, high-contrast, light, got "mine"`},
+		{`"themes": {"mine": {"accent": "purple"}}`, "themes", `mine: accent: invalid color "purple"`},
+		{`"themes": {"light": {"accent": "#8250df"}}`, "themes", "light: a built-in theme has that name"},
+		{`"themes": {"Mine": {}, "mine": {}}`, "themes", "mine: theme Mine has the same name"},
+		{`"themes": {"mine": {"base": "sepia"}}`, "themes", `mine: unknown base "sepia"`},
+		{`"themes": ["mine"]`, "themes", "expected an object of themes"},
+	}
+	for _, tt := range tests {
+		_, err := Parse([]byte(`{` + requiredJSON + `, ` + tt.json + `}`))
+		verr, ok := err.(*ValidationError)
+		if !ok {
+			t.Errorf("%s: Parse = %v, want a ValidationError", tt.json, err)
+			continue
+		}
+		found := false
+		for _, issue := range verr.Issues {
+			if issue.Key == tt.key && strings.Contains(issue.Message, tt.msg) && issue.Line > 0 {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: issues %v, want %s: %s with a position", tt.json, verr.Issues, tt.key, tt.msg)
+		}
+	}
+}
+
+func TestResolveReadOnly(t *testing.T) {
+	t.Setenv(paths.HomeEnv, t.TempDir())
+
+	resolved, err := ResolveReadOnly(map[string]string{"daily_goal": "6"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Config.DailyGoal != 6 || resolved.Origins["daily_goal"] != OriginFlag {
+		t.Errorf("daily_goal = %d from %s, want 6 from the flag", resolved.Config.DailyGoal, resolved.Origins["daily_goal"])
+	}
+	if _, err := os.Stat(ConfigPath()); !os.IsNotExist(err) {
+		t.Errorf("ResolveReadOnly wrote %s", ConfigPath())
+	}
+
+	if _, err := Resolve(nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(ConfigPath()); err != nil {
+		t.Errorf("Resolve didn't create the config file: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Duration is a time.Duration stored in config as a Go duration string such
+// as "25m" or "90s". Bare numbers are read as minutes for compatibility with
+// the old *_minutes keys.
+type Duration time.Duration
+
+// legacyKeys maps the old integer-minute keys to their duration keys.
+var legacyKeys = map[string]string{
+	"work_duration_minutes":        "work_duration",
+	"short_break_duration_minutes": "short_break_duration",
+	"long_break_duration_minutes":  "long_break_duration",
+}
+
+func ParseDuration(s string) (Duration, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return Duration(n * float64(time.Minute)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q (use e.g. 25m, 90s or 1h15m)", s)
+	}
+	return Duration(d), nil
+}
+
+// String formats d without zero trailing units: "25m", "1m30s", "1h15m".
+func (d Duration) String() string {
+	s := time.Duration(d).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n float64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return errors.New(`expected duration string such as "25m" or "90s"`)
+		}
+		s = strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	parsed, err := ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldsByKey maps JSON keys to their struct field index in Config.
+func fieldsByKey() map[string]int {
+	t := reflect.TypeOf(Config{})
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if key != "" && key != "-" {
+			fields[key] = i
+		}
+	}
+	return fields
+}
+
+// Keys returns every config key in declaration order.
+func Keys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if key != "" && key != "-" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+var durationType = reflect.TypeOf(Duration(0))
+
+// Get returns the value of key as a string. The legacy *_minutes keys are
+// still readable and report whole minutes.
+func (c Config) Get(key string) (string, error) {
+	if newKey, ok := legacyKeys[key]; ok {
+		d := reflect.ValueOf(c).Field(fieldsByKey()[newKey]).Interface().(Duration)
+		return strconv.Itoa(int(time.Duration(d) / time.Minute)), nil
+	}
+	idx, ok := fieldsByKey()[key]
+	if !ok {
+		return "", fmt.Errorf("unknown key %q", key)
+	}
+	return fmt.Sprint(reflect.ValueOf(c).Field(idx).Interface()), nil
+}
+
+// Set parses value according to the type of key and stores it. Duration
+// fields take Go duration strings ("25m", "90s") or bare minutes; lists and
+// goals their list form ("sat,sun", "fri=8,sat=off") or JSON.
+func (c *Config) Set(key, value string) error {
+	if newKey, ok := legacyKeys[key]; ok {
+		key = newKey
+	}
+	idx, ok := fieldsByKey()[key]
+	if !ok {
+		return fmt.Errorf("unknown key %q", key)
+	}
+
+	field := reflect.ValueOf(c).Elem().Field(idx)
+	if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		return nil
+	}
+	if field.Type() == durationType {
+		d, err := ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected integer, got %q", key, value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: expected true or false, got %q", key, value)
+		}
+		field.SetBool(b)
+	case reflect.String:
+		field.SetString(value)
+	default:
+		return fmt.Errorf("%s: unsupported type %s", key, field.Kind())
+	}
+	return nil
+}
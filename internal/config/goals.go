@@ -0,0 +1,288 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GoalOff is the goal of a day off, which never breaks a streak.
+const GoalOff = 0
+
+// WeekdayGoals are goals by weekday, the weekday_goals setting. In the config
+// file they are an object such as {"fri": 8, "sat": "off"}; on the command
+// line and in the environment a list such as "fri=8,sat=off".
+type WeekdayGoals map[time.Weekday]int
+
+// GoalOverrides are goals for specific dates, the goal_overrides setting: an
+// object such as {"2026-12-24": 4, "2026-12-25": "off"} in the config file,
+// or a list such as "2026-12-24=4,2026-12-25=off".
+type GoalOverrides map[string]int
+
+// ParseWeekdayGoals reads per-weekday goals such as "fri=8,sat=off".
+func ParseWeekdayGoals(s string) (WeekdayGoals, error) {
+	goals := make(WeekdayGoals)
+	err := parseGoalList(s, func(key string, goal int) error {
+		day, ok := weekdayByName(strings.ToLower(key))
+		if !ok {
+			return fmt.Errorf("unknown weekday %q", key)
+		}
+		goals[day] = goal
+		return nil
+	})
+	return goals, err
+}
+
+// ParseGoalOverrides reads goals for specific dates such as
+// "2026-12-24=4,2026-12-25=off".
+func ParseGoalOverrides(s string) (GoalOverrides, error) {
+	goals := make(GoalOverrides)
+	err := parseGoalList(s, func(key string, goal int) error {
+		if _, err := time.Parse("2006-01-02", key); err != nil {
+			return fmt.Errorf("invalid date %q (use YYYY-MM-DD)", key)
+		}
+		goals[key] = goal
+		return nil
+	})
+	return goals, err
+}
+
+// ParseGoal reads a goal: a number of pomodoros or "off".
+func ParseGoal(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "off") {
+		return GoalOff, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || n > 100 {
+		return 0, fmt.Errorf("goal must be between 0 and 100 or off, got %q", s)
+	}
+	return n, nil
+}
+
+func parseGoalList(s string, add func(key string, goal int) error) error {
+	for _, entry := range strings.Split(s, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("invalid entry %q (use key=goal)", strings.TrimSpace(entry))
+		}
+		goal, err := ParseGoal(value)
+		if err != nil {
+			return err
+		}
+		if err := add(strings.TrimSpace(key), goal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// String is the list form of g, Monday first.
+func (g WeekdayGoals) String() string {
+	var parts []string
+	for _, day := range weekOrder(g) {
+		parts = append(parts, weekdayName(day)+"="+formatGoal(g[day]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (g WeekdayGoals) MarshalJSON() ([]byte, error) {
+	var entries []goalEntry
+	for _, day := range weekOrder(g) {
+		entries = append(entries, goalEntry{weekdayName(day), g[day]})
+	}
+	return marshalGoals(entries)
+}
+
+func (g *WeekdayGoals) UnmarshalJSON(data []byte) error {
+	if list, ok := listForm(data); ok {
+		return g.UnmarshalText([]byte(list))
+	}
+	goals := make(WeekdayGoals)
+	err := unmarshalGoals(data, `{"fri": 8, "sat": "off"}`, func(key string, goal int) error {
+		day, ok := weekdayByName(strings.ToLower(key))
+		if !ok {
+			return fmt.Errorf("unknown weekday %q", key)
+		}
+		goals[day] = goal
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*g = goals
+	return nil
+}
+
+// UnmarshalText reads the list form, or the JSON object.
+func (g *WeekdayGoals) UnmarshalText(text []byte) error {
+	if isObject(text) {
+		return g.UnmarshalJSON(text)
+	}
+	goals, err := ParseWeekdayGoals(string(text))
+	if err != nil {
+		return err
+	}
+	*g = goals
+	return nil
+}
+
+// weekOrder returns the days in g, Monday first.
+func weekOrder(g WeekdayGoals) []time.Weekday {
+	var days []time.Weekday
+	for i := 1; i <= 7; i++ {
+		if _, ok := g[time.Weekday(i%7)]; ok {
+			days = append(days, time.Weekday(i%7))
+		}
+	}
+	return days
+}
+
+// String is the list form of g, oldest first.
+func (g GoalOverrides) String() string {
+	var parts []string
+	for _, date := range g.dates() {
+		parts = append(parts, date+"="+formatGoal(g[date]))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (g GoalOverrides) MarshalJSON() ([]byte, error) {
+	var entries []goalEntry
+	for _, date := range g.dates() {
+		entries = append(entries, goalEntry{date, g[date]})
+	}
+	return marshalGoals(entries)
+}
+
+func (g *GoalOverrides) UnmarshalJSON(data []byte) error {
+	if list, ok := listForm(data); ok {
+		return g.UnmarshalText([]byte(list))
+	}
+	goals := make(GoalOverrides)
+	err := unmarshalGoals(data, `{"2026-12-24": 4, "2026-12-25": "off"}`, func(key string, goal int) error {
+		if _, err := time.Parse("2006-01-02", key); err != nil {
+			return fmt.Errorf("invalid date %q (use YYYY-MM-DD)", key)
+		}
+		goals[key] = goal
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*g = goals
+	return nil
+}
+
+// UnmarshalText reads the list form, or the JSON object.
+func (g *GoalOverrides) UnmarshalText(text []byte) error {
+	if isObject(text) {
+		return g.UnmarshalJSON(text)
+	}
+	goals, err := ParseGoalOverrides(string(text))
+	if err != nil {
+		return err
+	}
+	*g = goals
+	return nil
+}
+
+// dates returns the dates in g, oldest first.
+func (g GoalOverrides) dates() []string {
+	dates := make([]string, 0, len(g))
+	for date := range g {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+type goalEntry struct {
+	key  string
+	goal int
+}
+
+// marshalGoals writes entries as a JSON object in their order, with days
+// off as "off".
+func marshalGoals(entries []goalEntry) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, e := range entries {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		key, _ := json.Marshal(e.key)
+		b.Write(key)
+		b.WriteString(": ")
+		if e.goal == GoalOff {
+			b.WriteString(`"off"`)
+		} else {
+			b.WriteString(strconv.Itoa(e.goal))
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// unmarshalGoals reads a JSON object of goals, each a number or "off", and
+// passes them to add in key order. example shows the expected object.
+func unmarshalGoals(data []byte, example string, add func(key string, goal int) error) error {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("expected an object of goals such as %s", example)
+	}
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var goal int
+		var err error
+		switch v := raw[key].(type) {
+		case float64:
+			goal, err = ParseGoal(strconv.FormatFloat(v, 'f', -1, 64))
+		case string:
+			goal, err = ParseGoal(v)
+		default:
+			err = fmt.Errorf(`goal must be a number or "off", got %v`, v)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if err := add(key, goal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listForm returns the string in data when it is a JSON string: the list
+// form that config files had before lists were stored as JSON.
+func listForm(data []byte) (string, bool) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// isObject reports whether text is JSON rather than the list form.
+func isObject(text []byte) bool {
+	text = bytes.TrimSpace(text)
+	return len(text) > 0 && (text[0] == '{' || text[0] == '[')
+}
+
+func formatGoal(goal int) string {
+	if goal == GoalOff {
+		return "off"
+	}
+	return strconv.Itoa(goal)
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGoalsJSON(t *testing.T) {
+	cfg := Default()
+	cfg.RestDays = Weekdays{time.Saturday, time.Sunday}
+	cfg.WeekdayGoals = WeekdayGoals{time.Sunday: 2, time.Friday: 6, time.Monday: GoalOff}
+	cfg.GoalOverrides = GoalOverrides{"2026-12-25": GoalOff, "2026-12-24": 4}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"rest_days":["sat","sun"]`,
+		`"weekday_goals":{"mon":"off","fri":6,"sun":2}`,
+		`"goal_overrides":{"2026-12-24":4,"2026-12-25":"off"}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s\nmissing %s", data, want)
+		}
+	}
+
+	got, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, cfg) {
+		t.Errorf("round trip:\ngot  %+v\nwant %+v", got, cfg)
+	}
+}
+
+// Config files written before these settings were JSON hold them as lists.
+func TestGoalsListForm(t *testing.T) {
+	data := `{"work_duration": "25m", "short_break_duration": "5m", "long_break_duration": "20m",
+		"long_break_after_intervals": 4, "daily_goal": 8,
+		"rest_days": "sat,sun", "weekday_goals": "fri=6,sat=off", "goal_overrides": "2026-12-25=off"}`
+	cfg, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Weekdays{time.Saturday, time.Sunday}); !reflect.DeepEqual(cfg.RestDays, want) {
+		t.Errorf("rest_days = %v, want %v", cfg.RestDays, want)
+	}
+	if want := (WeekdayGoals{time.Friday: 6, time.Saturday: GoalOff}); !reflect.DeepEqual(cfg.WeekdayGoals, want) {
+		t.Errorf("weekday_goals = %v, want %v", cfg.WeekdayGoals, want)
+	}
+	if want := (GoalOverrides{"2026-12-25": GoalOff}); !reflect.DeepEqual(cfg.GoalOverrides, want) {
+		t.Errorf("goal_overrides = %v, want %v", cfg.GoalOverrides, want)
+	}
+}
+
+func TestGoalsSetGet(t *testing.T) {
+	tests := []struct {
+		key, value, want string
+	}{
+		{"rest_days", "Saturday, sun", "sat,sun"},
+		{"rest_days", `["fri"]`, "fri"},
+		{"rest_days", "", ""},
+		{"weekday_goals", "sat=off,fri=6", "fri=6,sat=off"},
+		{"weekday_goals", `{"tue": 3}`, "tue=3"},
+		{"goal_overrides", "2026-12-25=off,2026-12-24=4", "2026-12-24=4,2026-12-25=off"},
+		{"goal_overrides", `{"2027-01-01": "2"}`, "2027-01-01=2"},
+	}
+	for _, tt := range tests {
+		cfg := Default()
+		if err := cfg.Set(tt.key, tt.value); err != nil {
+			t.Errorf("Set(%s, %q): %v", tt.key, tt.value, err)
+			continue
+		}
+		if got, _ := cfg.Get(tt.key); got != tt.want {
+			t.Errorf("Set(%s, %q) then Get = %q, want %q", tt.key, tt.value, got, tt.want)
+		}
+	}
+
+	for _, tt := range []struct{ key, value string }{
+		{"rest_days", "someday"},
+		{"weekday_goals", "fri"},
+		{"weekday_goals", "fri=101"},
+		{"weekday_goals", `{"fri": true}`},
+		{"goal_overrides", "12/25=off"},
+	} {
+		cfg := Default()
+		if err := cfg.Set(tt.key, tt.value); err == nil {
+			t.Errorf("Set(%s, %q) succeeded", tt.key, tt.value)
+		}
+	}
+}
+
+func TestGoalsInvalidJSON(t *testing.T) {
+	data := `{"work_duration": "25m", "short_break_duration": "5m", "long_break_duration": "20m",
+		"long_break_after_intervals": 4, "daily_goal": 8,
+		"rest_days": ["funday"], "weekday_goals": {"fri": 500}, "goal_overrides": [1]}`
+	_, err := Parse([]byte(data))
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Parse = %v, want a ValidationError", err)
+	}
+	keys := make(map[string]bool)
+	for _, issue := range verr.Issues {
+		keys[issue.Key] = true
+		if issue.Line == 0 {
+			t.Errorf("%s: no position", issue)
+		}
+	}
+	for _, key := range []string{"rest_days", "weekday_goals", "goal_overrides"} {
+		if !keys[key] {
+			t.Errorf("no issue for %s in %v", key, verr)
+		}
+	}
+}
+
+func TestFlagKeysSkipStructuredKeys(t *testing.T) {
+	for _, key := range FlagKeys() {
+		switch key {
+		case "rest_days", "weekday_goals", "goal_overrides", "theme_colors":
+			t.Errorf("FlagKeys includes %s", key)
+		}
+	}
+}
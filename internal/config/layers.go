@@ -0,0 +1,168 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Origin records which layer supplied a config value.
+type Origin string
+
+const (
+	OriginDefault Origin = "default"
+	OriginFile    Origin = "file"
+	OriginEnv     Origin = "env"
+	OriginFlag    Origin = "flag"
+)
+
+// Resolved is the effective config after layering defaults < file < env <
+// flags, together with the origin of every key.
+type Resolved struct {
+	Config  Config            `json:"config"`
+	Origins map[string]Origin `json:"origins"`
+}
+
+// EnvName returns the environment variable overriding key, e.g.
+// work_duration -> POMME_WORK_DURATION.
+func EnvName(key string) string {
+	return "POMME_" + strings.ToUpper(key)
+}
+
+// FlagName returns the command-line flag overriding key, e.g.
+// work_duration -> work, daily_goal -> daily-goal.
+func FlagName(key string) string {
+	name := strings.TrimSuffix(key, "_duration")
+	return strings.ReplaceAll(name, "_", "-")
+}
+
+// Resolve loads the config file and applies environment and flag overrides.
+// flags maps config keys to raw values, as collected by RegisterFlags.
+func Resolve(flags map[string]string) (Resolved, error) {
+	return resolve(flags, true)
+}
+
+// ResolveReadOnly is Resolve without creating a missing config file, for
+// commands that status bars run every few seconds.
+func ResolveReadOnly(flags map[string]string) (Resolved, error) {
+	return resolve(flags, false)
+}
+
+func resolve(flags map[string]string, create bool) (Resolved, error) {
+	cfg, fromFile, err := loadFile(create)
+	if err != nil {
+		return Resolved{Config: cfg}, err
+	}
+
+	origins := make(map[string]Origin)
+	for _, key := range Keys() {
+		origins[key] = OriginDefault
+		if fromFile[key] {
+			origins[key] = OriginFile
+		}
+	}
+
+	var issues []Issue
+	for _, key := range Keys() {
+		value, ok := os.LookupEnv(EnvName(key))
+		if !ok {
+			continue
+		}
+		if err := cfg.Set(key, value); err != nil {
+			issues = append(issues, Issue{Key: EnvName(key), Message: err.Error()})
+			continue
+		}
+		origins[key] = OriginEnv
+	}
+
+	for key, value := range flags {
+		if err := cfg.Set(key, value); err != nil {
+			issues = append(issues, Issue{Key: "--" + FlagName(key), Message: err.Error()})
+			continue
+		}
+		origins[key] = OriginFlag
+	}
+
+	if err := cfg.Validate(); err != nil {
+		if verr, ok := err.(*ValidationError); ok {
+			for _, issue := range verr.Issues {
+				issue.Message = fmt.Sprintf("%s (from %s)", issue.Message, origins[issue.Key])
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		return Resolved{Config: cfg, Origins: origins}, &ValidationError{Issues: issues}
+	}
+	return Resolved{Config: cfg, Origins: origins}, nil
+}
+
+type overrideFlag struct {
+	key       string
+	overrides map[string]string
+	isBool    bool
+}
+
+func (f *overrideFlag) String() string { return "" }
+
+func (f *overrideFlag) Set(value string) error {
+	var scratch Config
+	if err := scratch.Set(f.key, value); err != nil {
+		return err
+	}
+	f.overrides[f.key] = value
+	return nil
+}
+
+func (f *overrideFlag) IsBoolFlag() bool { return f.isBool }
+
+// noFlagKeys hold lists in a string; like the keys holding JSON arrays and
+// objects, they are set in the config file or through the environment, not
+// by flags.
+var noFlagKeys = map[string]bool{
+	"theme_colors": true,
+}
+
+// FlagKeys returns the keys RegisterFlags adds flags for: the scalar,
+// user-facing ones.
+func FlagKeys() []string {
+	fields := fieldsByKey()
+	t := reflect.TypeOf(Config{})
+	var keys []string
+	for _, key := range Keys() {
+		switch t.Field(fields[key]).Type.Kind() {
+		case reflect.Int, reflect.Int64, reflect.Bool, reflect.String:
+			if !noFlagKeys[key] {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// RegisterFlags adds a flag for each of FlagKeys to fs. Values given on the
+// command line are type-checked and collected into overrides for Resolve.
+func RegisterFlags(fs *flag.FlagSet, overrides map[string]string) {
+	fields := fieldsByKey()
+	t := reflect.TypeOf(Config{})
+	for _, key := range FlagKeys() {
+		isBool := t.Field(fields[key]).Type.Kind() == reflect.Bool
+		fs.Var(&overrideFlag{key: key, overrides: overrides, isBool: isBool},
+			FlagName(key), fmt.Sprintf("Override %s (env %s)", key, EnvName(key)))
+	}
+}
+
+// Args renders overrides back into command-line flags, for passing them on
+// to a child process.
+func Args(overrides map[string]string) []string {
+	var args []string
+	for _, key := range Keys() {
+		if value, ok := overrides[key]; ok {
+			args = append(args, "--"+FlagName(key)+"="+value)
+		}
+	}
+	return args
+}
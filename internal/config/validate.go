@@ -0,0 +1,374 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/theme"
+)
+
+// Issue is a single problem found in a config file. Line and Column are
+// 1-based and zero when the problem has no position (e.g. a missing key).
+type Issue struct {
+	Key     string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (i Issue) String() string {
+	var b strings.Builder
+	if i.Line > 0 {
+		fmt.Fprintf(&b, "%d:%d: ", i.Line, i.Column)
+	}
+	if i.Key != "" {
+		fmt.Fprintf(&b, "%s: ", i.Key)
+	}
+	b.WriteString(i.Message)
+	return b.String()
+}
+
+type ValidationError struct {
+	Path   string
+	Issues []Issue
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		if e.Path != "" {
+			lines[i] = e.Path + ":" + issue.String()
+		} else {
+			lines[i] = issue.String()
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+type intRange struct {
+	min, max int
+}
+
+type durationRange struct {
+	min, max time.Duration
+}
+
+var durationRanges = map[string]durationRange{
+	"work_duration":        {time.Second, 4 * time.Hour},
+	"short_break_duration": {time.Second, 2 * time.Hour},
+	"long_break_duration":  {time.Second, 4 * time.Hour},
+}
+
+// Ranges for integer fields. Keys not listed here accept any value.
+var intRanges = map[string]intRange{
+	"long_break_after_intervals": {1, 100},
+	"daily_goal":                 {1, 100},
+	"simplebar_widget_id":        {1, 1000},
+	"simplebar_port":             {1, 65535},
+	"ics_port":                   {0, 65535},
+	"chart_width":                {64, 4096},
+	"chart_height":               {48, 4096},
+	"graph_pixel_ratio":          {1, 4},
+}
+
+// Allowed values for string fields with a fixed set of choices.
+var stringChoices = map[string][]string{
+	"journal_format": {"md", "org"},
+	"graph_renderer": {"auto", "kitty", "sixel", "iterm2", "blocks"},
+	"graph_scale":    {"auto", "goal"},
+}
+
+// clockKeys hold a time of day as HH:MM.
+var clockKeys = []string{"journal_time"}
+
+// requiredKeys must be present in a config file; durations may instead use
+// their legacy *_minutes key.
+var requiredKeys = []string{
+	"work_duration",
+	"short_break_duration",
+	"long_break_duration",
+	"long_break_after_intervals",
+	"daily_goal",
+}
+
+// Parse decodes and validates a config file. Keys missing from data keep
+// their default values; every problem found is reported, not just the first.
+func Parse(data []byte) (Config, error) {
+	cfg, _, err := parse(data)
+	return cfg, err
+}
+
+// parse is Parse, also reporting which keys the file sets.
+func parse(data []byte) (Config, map[string]bool, error) {
+	cfg := Default()
+
+	values, offsets, err := topLevelKeys(data)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			// Offset counts the offending byte, so step back onto it.
+			line, col := position(data, int(syntaxErr.Offset)-1)
+			return cfg, nil, &ValidationError{Issues: []Issue{{Line: line, Column: col, Message: syntaxErr.Error()}}}
+		}
+		return cfg, nil, &ValidationError{Issues: []Issue{{Message: err.Error()}}}
+	}
+
+	var issues []Issue
+	at := func(key, msg string) Issue {
+		issue := Issue{Key: key, Message: msg}
+		if off, ok := offsets[key]; ok {
+			issue.Line, issue.Column = position(data, off)
+		}
+		return issue
+	}
+
+	set := make(map[string]bool, len(values))
+	fields := fieldsByKey()
+	v := reflect.ValueOf(&cfg).Elem()
+	for _, key := range sortedByOffset(offsets) {
+		if newKey, ok := legacyKeys[key]; ok {
+			if _, both := values[newKey]; both {
+				issues = append(issues, at(key, "duplicates "+newKey+"; remove one"))
+				continue
+			}
+			var minutes int
+			if err := json.Unmarshal(values[key], &minutes); err != nil {
+				issues = append(issues, at(key, "expected integer"))
+				continue
+			}
+			v.Field(fields[newKey]).SetInt(int64(time.Duration(minutes) * time.Minute))
+			offsets[newKey] = offsets[key]
+			set[newKey] = true
+			continue
+		}
+
+		idx, ok := fields[key]
+		if !ok {
+			issues = append(issues, at(key, "unknown key"))
+			continue
+		}
+		field := v.Field(idx)
+		if err := json.Unmarshal(values[key], field.Addr().Interface()); err != nil {
+			msg := "expected " + kindName(field.Kind())
+			if _, ok := field.Addr().Interface().(json.Unmarshaler); ok {
+				msg = err.Error()
+			}
+			issues = append(issues, at(key, msg))
+			continue
+		}
+		set[key] = true
+	}
+
+	for _, key := range requiredKeys {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		if legacy := legacyKeyFor(key); legacy != "" {
+			if _, ok := values[legacy]; ok {
+				continue
+			}
+		}
+		issues = append(issues, Issue{Key: key, Message: "required key missing"})
+	}
+
+	if err := cfg.Validate(); err != nil {
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			for _, issue := range verr.Issues {
+				issues = append(issues, at(issue.Key, issue.Message))
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		return cfg, set, &ValidationError{Issues: issues}
+	}
+	return cfg, set, nil
+}
+
+// Validate checks field values against their allowed ranges.
+func (c Config) Validate() error {
+	var issues []Issue
+
+	fields := fieldsByKey()
+	v := reflect.ValueOf(c)
+	keys := make([]string, 0, len(intRanges))
+	for key := range intRanges {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		r := intRanges[key]
+		n := int(v.Field(fields[key]).Int())
+		if n < r.min || n > r.max {
+			issues = append(issues, Issue{
+				Key:     key,
+				Message: fmt.Sprintf("must be between %d and %d, got %d", r.min, r.max, n),
+			})
+		}
+	}
+
+	keys = keys[:0]
+	for key := range durationRanges {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		r := durationRanges[key]
+		d := time.Duration(v.Field(fields[key]).Int())
+		if d < r.min || d > r.max {
+			issues = append(issues, Issue{
+				Key:     key,
+				Message: fmt.Sprintf("must be between %s and %s, got %s", Duration(r.min), Duration(r.max), Duration(d)),
+			})
+		}
+	}
+
+	keys = keys[:0]
+	for key := range stringChoices {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		choices := stringChoices[key]
+		value := v.Field(fields[key]).String()
+		if !slices.Contains(choices, value) {
+			issues = append(issues, Issue{
+				Key:     key,
+				Message: fmt.Sprintf("must be one of %s, got %q", strings.Join(choices, ", "), value),
+			})
+		}
+	}
+
+	for _, key := range clockKeys {
+		value := v.Field(fields[key]).String()
+		if _, err := time.Parse("15:04", value); err != nil {
+			issues = append(issues, Issue{
+				Key:     key,
+				Message: fmt.Sprintf("must be a time of day as HH:MM, got %q", value),
+			})
+		}
+	}
+
+	// Goals are checked as they are read; this catches ones set in code.
+	if _, err := ParseWeekdayGoals(c.WeekdayGoals.String()); err != nil {
+		issues = append(issues, Issue{Key: "weekday_goals", Message: err.Error()})
+	}
+	if _, err := ParseGoalOverrides(c.GoalOverrides.String()); err != nil {
+		issues = append(issues, Issue{Key: "goal_overrides", Message: err.Error()})
+	}
+	if _, err := theme.ParseColors(c.ThemeColors); err != nil {
+		issues = append(issues, Issue{Key: "theme_colors", Message: err.Error()})
+	}
+	if err := c.Themes.Check(); err != nil {
+		issues = append(issues, Issue{Key: "themes", Message: err.Error()})
+	}
+	// theme is a built-in or one of themes, so its choices are not fixed.
+	// Case doesn't matter, as in theme.Load.
+	if !c.Themes.Has(c.Theme) {
+		issues = append(issues, Issue{
+			Key:     "theme",
+			Message: fmt.Sprintf("must be one of %s, got %q", strings.Join(c.Themes.Names(), ", "), c.Theme),
+		})
+	}
+
+	if len(issues) > 0 {
+		return &ValidationError{Issues: issues}
+	}
+	return nil
+}
+
+// topLevelKeys splits a JSON object into its raw values and records the
+// byte offset of each key so issues can point at a line and column.
+func topLevelKeys(data []byte) (map[string]json.RawMessage, map[string]int, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil, errors.New("config must be a JSON object")
+	}
+
+	values := make(map[string]json.RawMessage)
+	offsets := make(map[string]int)
+	for dec.More() {
+		start := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, _ := tok.(string)
+		if i := bytes.IndexByte(data[start:], '"'); i >= 0 {
+			offsets[key] = start + i
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+		values[key] = raw
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	return values, offsets, nil
+}
+
+func sortedByOffset(offsets map[string]int) []string {
+	keys := make([]string, 0, len(offsets))
+	for key := range offsets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return offsets[keys[i]] < offsets[keys[j]]
+	})
+	return keys
+}
+
+func position(data []byte, offset int) (line, col int) {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	line = 1 + bytes.Count(data[:offset], []byte{'\n'})
+	col = offset - bytes.LastIndexByte(data[:offset], '\n')
+	return line, col
+}
+
+func legacyKeyFor(key string) string {
+	for legacy, newKey := range legacyKeys {
+		if newKey == key {
+			return legacy
+		}
+	}
+	return ""
+}
+
+func kindName(k reflect.Kind) string {
+	switch k {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.String:
+		return "string"
+	default:
+		return k.String()
+	}
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Weekdays are a set of weekdays, such as the rest_days setting: an array of
+// names such as ["sat", "sun"] in the config file, or a list such as
+// "sat,sun" on the command line and in the environment.
+type Weekdays []time.Weekday
+
+// ParseWeekdays reads a comma-separated list of weekday names, full or
+// abbreviated to three letters, e.g. "sat,sun".
+func ParseWeekdays(s string) (Weekdays, error) {
+	var days Weekdays
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		day, ok := weekdayByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown weekday %q", name)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func weekdayByName(name string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		full := strings.ToLower(d.String())
+		if name == full || name == full[:3] {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// weekdayName is the three-letter name of day, e.g. "sat".
+func weekdayName(day time.Weekday) string {
+	return strings.ToLower(day.String()[:3])
+}
+
+// String is the list form of w.
+func (w Weekdays) String() string {
+	names := make([]string, len(w))
+	for i, day := range w {
+		names[i] = weekdayName(day)
+	}
+	return strings.Join(names, ",")
+}
+
+func (w Weekdays) MarshalJSON() ([]byte, error) {
+	names := make([]string, len(w))
+	for i, day := range w {
+		names[i] = weekdayName(day)
+	}
+	return json.Marshal(names)
+}
+
+func (w *Weekdays) UnmarshalJSON(data []byte) error {
+	if list, ok := listForm(data); ok {
+		return w.UnmarshalText([]byte(list))
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return fmt.Errorf(`expected an array of weekdays such as ["sat", "sun"]`)
+	}
+	days, err := ParseWeekdays(strings.Join(names, ","))
+	if err != nil {
+		return err
+	}
+	*w = days
+	return nil
+}
+
+// UnmarshalText reads the list form, or the JSON array.
+func (w *Weekdays) UnmarshalText(text []byte) error {
+	if isObject(text) {
+		return w.UnmarshalJSON(text)
+	}
+	days, err := ParseWeekdays(string(text))
+	if err != nil {
+		return err
+	}
+	*w = days
+	return nil
+}
@@ -14,12 +14,15 @@ import (
 	"github.com/philleif/pomme/internal/blocker"
 	"github.com/philleif/pomme/internal/config"
 	"github.com/philleif/pomme/internal/sparkline"
+	"github.com/philleif/pomme/internal/statusline"
 	"github.com/philleif/pomme/internal/storage"
 	"github.com/philleif/pomme/internal/timer"
+	"github.com/philleif/pomme/internal/warrior"
 )
 
 type Command struct {
-	Action string `json:"action"`
+	Action string            `json:"action"`
+	Params map[string]string `json:"params,omitempty"`
 }
 
 type Response struct {
@@ -35,57 +38,99 @@ type StatusData struct {
 	RemainingSeconds int    `json:"remaining_seconds"`
 	IntervalsToday   int    `json:"intervals_today"`
 	DailyGoal        int    `json:"daily_goal"`
+	DayOff           bool   `json:"day_off,omitempty"`
 	BlockEnabled     bool   `json:"block_enabled"`
 	AlwaysBlock      bool   `json:"always_block"`
 	Sparkline        string `json:"sparkline"`
 	StatusLine       string `json:"status_line"`
 	WeekValues       []int  `json:"week_values"`
+	WeekGoals        []int  `json:"week_goals"`
+	WeekFocusMinutes int    `json:"week_focus_minutes"`
+	Streak           int    `json:"streak"`
+	LongestStreak    int    `json:"longest_streak"`
+	ConfigError      string `json:"config_error,omitempty"`
+
+	CurrentTask *storage.Task `json:"current_task,omitempty"`
+
+	// Interruptions of the work interval in progress, as ' (internal) and
+	// - (external) marks.
+	Interruptions string `json:"interruptions,omitempty"`
 }
 
 type Daemon struct {
-	mu       sync.RWMutex
-	config   config.Config
-	timer    *timer.Timer
-	storage  *storage.Storage
-	blocker  *blocker.Blocker
-	listener net.Listener
+	mu        sync.RWMutex
+	config    config.Config
+	origins   map[string]config.Origin
+	overrides map[string]string
+	timer     *timer.Timer
+	storage   *storage.Storage
+	blocker   *blocker.Blocker
+	listener  net.Listener
 
 	onStatusChange func(StatusData)
 	stopChan       chan struct{}
 	lastDate       string
+	configModTime  time.Time
+	configErr      error
+	currentTask    *storage.Task
+	pendingNote    string
+	pendingTags    []string
+	icsServer      *http.Server
+
+	timew    *warrior.Timewarrior
+	taskw    *warrior.Taskwarrior
+	tracking bool
+
+	journalDate    string
+	journalRetryAt time.Time
+
+	// The status's streak, computed once per day and again when an
+	// interval is recorded or the config reloaded; see currentStreaks.
+	streak    storage.Streaks
+	streakDay string
+	streakGen int
 }
 
-func New() (*Daemon, error) {
-	cfg, err := config.Load()
+// New creates a daemon from the layered config. overrides holds flag values
+// keyed by config key; they are re-applied on every reload.
+func New(overrides map[string]string) (*Daemon, error) {
+	resolved, err := config.Resolve(overrides)
 	if err != nil {
-		cfg = config.Default()
+		return nil, fmt.Errorf("invalid config:\n%w", err)
 	}
+	cfg := resolved.Config
 
 	store, err := storage.New()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open storage: %w", err)
 	}
 
-	timerCfg := timer.Config{
-		WorkDuration:       cfg.WorkDurationTime(),
-		ShortBreakDuration: cfg.ShortBreakDurationTime(),
-		LongBreakDuration:  cfg.LongBreakDurationTime(),
-		LongBreakAfter:     cfg.LongBreakAfter,
-	}
-
-	t := timer.New(timerCfg)
+	t := timer.New(timerConfig(cfg))
 	b := blocker.New()
 
 	d := &Daemon{
-		config:   cfg,
-		timer:    t,
-		storage:  store,
-		blocker:  b,
-		lastDate: time.Now().Format("2006-01-02"),
+		config:        cfg,
+		origins:       resolved.Origins,
+		overrides:     overrides,
+		timer:         t,
+		storage:       store,
+		blocker:       b,
+		lastDate:      time.Now().Format("2006-01-02"),
+		configModTime: configModTime(),
+		timew:         warrior.NewTimewarrior(),
+		taskw:         warrior.NewTaskwarrior(),
 	}
 
 	todayCount, _ := store.TodayCount()
 	t.SetIntervalsToday(todayCount)
+	d.journalDate, _ = store.State(journalStateKey)
+	store.RecordGoal(goalChange(cfg, d.lastDate))
+
+	if id, _ := store.CurrentTaskID(); id != 0 {
+		if task, err := store.Task(id); err == nil && task.Status == storage.TaskActive {
+			d.currentTask = &task
+		}
+	}
 
 	t.SetOnComplete(d.onPhaseComplete)
 
@@ -96,15 +141,116 @@ func New() (*Daemon, error) {
 	return d, nil
 }
 
+func timerConfig(cfg config.Config) timer.Config {
+	return timer.Config{
+		WorkDuration:       cfg.WorkDurationTime(),
+		ShortBreakDuration: cfg.ShortBreakDurationTime(),
+		LongBreakDuration:  cfg.LongBreakDurationTime(),
+		LongBreakAfter:     cfg.LongBreakAfter,
+	}
+}
+
+func configModTime() time.Time {
+	info, err := os.Stat(config.ConfigPath())
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// ReloadConfig re-reads the config file and applies it to the timer, blocker
+// and notifiers. An invalid file is rejected and the current config kept.
+func (d *Daemon) ReloadConfig() error {
+	resolved, err := config.Resolve(d.overrides)
+	cfg := resolved.Config
+
+	d.mu.Lock()
+	d.configModTime = configModTime()
+	if err != nil {
+		d.configErr = fmt.Errorf("invalid config: %w", err)
+		err = d.configErr
+		d.mu.Unlock()
+		d.notifyStatusChange()
+		return err
+	}
+	old := d.config
+	d.config = cfg
+	d.origins = resolved.Origins
+	d.configErr = nil
+	d.mu.Unlock()
+
+	d.timer.SetConfig(timerConfig(cfg))
+	d.storage.RecordGoal(goalChange(cfg, time.Now().Format("2006-01-02")))
+	d.invalidateStreaks()
+
+	// Only touch the blocker when the file changed, so runtime toggles survive
+	// unrelated edits.
+	if cfg.BlockMessages != old.BlockMessages {
+		d.blocker.SetEnabled(cfg.BlockMessages)
+	}
+	if cfg.AlwaysBlock != old.AlwaysBlock {
+		d.blocker.SetAlwaysBlock(cfg.AlwaysBlock)
+	}
+	if cfg.TimewarriorEnabled != old.TimewarriorEnabled {
+		d.setInInterval(d.timer.Phase() == timer.PhaseWork && d.timer.State() == timer.StateRunning)
+	}
+	if cfg.ICSPort != old.ICSPort {
+		if err := d.serveICS(cfg.ICSPort); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	}
+
+	d.notifyStatusChange()
+	return nil
+}
+
+func (d *Daemon) Config() config.Config {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.config
+}
+
+func (d *Daemon) ResolvedConfig() config.Resolved {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return config.Resolved{Config: d.config, Origins: d.origins}
+}
+
+func (d *Daemon) watchConfig() {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopChan:
+			return
+		case <-ticker.C:
+			modTime := configModTime()
+			d.mu.RLock()
+			changed := !modTime.IsZero() && !modTime.Equal(d.configModTime)
+			d.mu.RUnlock()
+			if changed {
+				d.ReloadConfig()
+			}
+		}
+	}
+}
+
 func (d *Daemon) onPhaseComplete(phase timer.Phase) {
 	if phase == timer.PhaseWork {
-		d.storage.RecordInterval()
+		startedAt, elapsed := d.timer.LastPhase()
+		iv := storage.Interval{TaskID: d.currentTaskID(), StartedAt: startedAt, Duration: elapsed}
+		if id, err := d.storage.RecordInterval(iv); err == nil {
+			d.applyPendingNote(id)
+		}
+		d.invalidateStreaks()
+		d.refreshCurrentTask()
 		d.sendNotification("Work interval complete!", "Time for a break.")
 	} else {
 		d.sendNotification("Break complete!", "Ready to focus?")
 	}
 
-	d.blocker.SetInInterval(d.timer.Phase() == timer.PhaseWork && d.timer.State() == timer.StateRunning)
+	d.setInInterval(d.timer.Phase() == timer.PhaseWork && d.timer.State() == timer.StateRunning)
 	d.notifyStatusChange()
 }
 
@@ -114,11 +260,12 @@ func (d *Daemon) sendNotification(title, message string) {
 }
 
 func (d *Daemon) refreshSimpleBar() {
-	if !d.config.SimpleBarEnabled {
+	cfg := d.Config()
+	if !cfg.SimpleBarEnabled {
 		return
 	}
 	url := fmt.Sprintf("http://localhost:%d/widget/user-widget/refresh/%d",
-		d.config.SimpleBarPort, d.config.SimpleBarWidgetID)
+		cfg.SimpleBarPort, cfg.SimpleBarWidgetID)
 	client := &http.Client{Timeout: 500 * time.Millisecond}
 	client.Get(url)
 }
@@ -153,9 +300,14 @@ func (d *Daemon) Start(socketPath string) error {
 	d.blocker.SetEnabled(true)
 	d.blocker.Start()
 
+	if err := d.serveICS(d.Config().ICSPort); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+	}
+
 	d.stopChan = make(chan struct{})
 	go d.acceptConnections()
 	go d.statusUpdateLoop()
+	go d.watchConfig()
 
 	return nil
 }
@@ -170,6 +322,7 @@ func (d *Daemon) statusUpdateLoop() {
 			return
 		case <-ticker.C:
 			d.checkDateChange()
+			d.checkJournal()
 			if d.timer.State() == timer.StateRunning {
 				d.notifyStatusChange()
 			}
@@ -201,6 +354,8 @@ func (d *Daemon) Stop() {
 	if d.listener != nil {
 		d.listener.Close()
 	}
+	d.serveICS(0)
+	d.setInInterval(false)
 	d.blocker.Stop()
 	d.storage.Close()
 }
@@ -239,6 +394,11 @@ func (d *Daemon) handleConnection(conn net.Conn) {
 	d.sendResponse(conn, resp)
 }
 
+// Handle runs a command as if it had arrived on the socket.
+func (d *Daemon) Handle(cmd Command) Response {
+	return d.handleCommand(cmd)
+}
+
 func (d *Daemon) handleCommand(cmd Command) Response {
 	switch cmd.Action {
 	case "status":
@@ -250,25 +410,26 @@ func (d *Daemon) handleCommand(cmd Command) Response {
 		} else {
 			d.timer.Start()
 		}
-		d.blocker.SetInInterval(d.timer.Phase() == timer.PhaseWork)
+		d.setInInterval(d.timer.Phase() == timer.PhaseWork)
 		d.notifyStatusChange()
 		return Response{Success: true, Data: d.GetStatus()}
 
 	case "pause":
 		d.timer.Pause()
-		d.blocker.SetInInterval(false)
+		d.setInInterval(false)
 		d.notifyStatusChange()
 		return Response{Success: true, Data: d.GetStatus()}
 
 	case "skip":
 		d.timer.Skip()
-		d.blocker.SetInInterval(false)
+		d.setInInterval(false)
 		d.notifyStatusChange()
 		return Response{Success: true, Data: d.GetStatus()}
 
 	case "reset":
 		d.timer.Reset()
-		d.blocker.SetInInterval(false)
+		d.clearPendingNote()
+		d.setInInterval(false)
 		d.notifyStatusChange()
 		return Response{Success: true, Data: d.GetStatus()}
 
@@ -282,6 +443,48 @@ func (d *Daemon) handleCommand(cmd Command) Response {
 		d.notifyStatusChange()
 		return Response{Success: true, Data: d.GetStatus()}
 
+	case "config":
+		return Response{Success: true, Data: d.ResolvedConfig()}
+
+	case "task_add", "task_list", "task_done", "task_select", "task_pick":
+		return d.handleTaskCommand(cmd)
+
+	case "note":
+		return d.handleNote(cmd)
+
+	case "interrupt":
+		return d.handleInterrupt(cmd)
+
+	case "stats":
+		stats, err := d.Stats(cmd.Params["tag"])
+		if err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		return Response{Success: true, Data: stats}
+
+	case "invalidate_stats":
+		d.InvalidateStats()
+		return Response{Success: true, Data: d.GetStatus()}
+
+	case "stats_query":
+		return d.handleStatsQuery(cmd)
+
+	case "calendar":
+		return d.handleCalendar(cmd)
+
+	case "heatmap":
+		heatmap, err := d.storage.Heatmap(cmd.Params["from"], cmd.Params["to"])
+		if err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		return Response{Success: true, Data: heatmap}
+
+	case "reload_config":
+		if err := d.ReloadConfig(); err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		return Response{Success: true, Data: d.GetStatus()}
+
 	default:
 		return Response{Success: false, Error: "unknown action"}
 	}
@@ -294,51 +497,92 @@ func (d *Daemon) sendResponse(conn net.Conn, resp Response) {
 
 func (d *Daemon) GetStatus() StatusData {
 	status := d.timer.Status()
-	dailyGoal := d.config.DailyGoal
 
-	days, _ := d.storage.Last7Days()
-	intervals := make([]int, len(days))
-	for i, day := range days {
-		intervals[i] = day.Intervals
+	d.mu.RLock()
+	var configErr string
+	if d.configErr != nil {
+		configErr = d.configErr.Error()
+	}
+	currentTask := d.currentTask
+	d.mu.RUnlock()
+
+	now := time.Now()
+	weekStart := now.AddDate(0, 0, -6)
+	week, _ := d.storage.StatsQuery(storage.StatsQuery{
+		From:    weekStart.Format("2006-01-02"),
+		To:      now.Format("2006-01-02"),
+		GroupBy: storage.GroupDay,
+		Fill:    true,
+	})
+	intervals := make([]int, len(week))
+	weekFocus := 0
+	for i, day := range week {
+		intervals[i] = day.Count
+		weekFocus += day.FocusMinutes
 	}
-	spark := sparkline.GenerateBrailleSpaced(intervals, dailyGoal)
+	plan := d.goalPlan()
+	goals := plan.Goals(weekStart, len(intervals))
+	today := plan.On(now.Format("2006-01-02"))
+	spark := sparkline.GenerateBrailleSpacedGoals(intervals, goals)
+	streaks := d.currentStreaks()
 
 	remaining := status.Remaining
 	if remaining < 0 {
 		remaining = 0
 	}
-	mins := int(remaining.Minutes())
-	secs := int(remaining.Seconds()) % 60
 
-	var icon string
-	switch status.Phase {
-	case timer.PhaseWork:
-		icon = "🍅"
-	case timer.PhaseShortBreak, timer.PhaseLongBreak:
-		icon = "☕"
-	}
-
-	if status.State == timer.StatePaused {
-		icon = "⏸"
+	var marks string
+	if status.Phase == timer.PhaseWork && !status.PhaseStartedAt.IsZero() {
+		pending, _ := d.storage.PendingInterruptions(status.PhaseStartedAt)
+		marks = storage.InterruptionMarks(pending)
 	}
 
 	// Enhanced status line with subscript for today's count
-	timeStr := fmt.Sprintf("%02d:%02d", mins, secs)
-	statusLine := sparkline.CompactStatus(icon, timeStr, spark, status.IntervalsToday)
+	timeStr := formatRemaining(remaining)
+	state := statusline.State(status.State.String(), status.Phase.String())
+	if state == "idle" {
+		// An idle timer keeps the icon of the phase it will start.
+		state = statusline.State("running", status.Phase.String())
+	}
+	statusLine, _ := statusline.Format(statusline.Status{
+		State:     state,
+		Icon:      statusline.Icon(state),
+		Remaining: timeStr,
+		Sparkline: spark,
+		Today:     status.IntervalsToday,
+	}, "default")
 
 	return StatusData{
 		TimerState:       status.State.String(),
 		Phase:            status.Phase.String(),
-		Remaining:        fmt.Sprintf("%02d:%02d", mins, secs),
+		Remaining:        timeStr,
 		RemainingSeconds: int(remaining.Seconds()),
 		IntervalsToday:   status.IntervalsToday,
-		DailyGoal:        dailyGoal,
+		DailyGoal:        today.Goal,
+		DayOff:           today.Off,
 		BlockEnabled:     d.blocker.Enabled(),
 		AlwaysBlock:      d.blocker.AlwaysBlock(),
 		Sparkline:        spark,
 		StatusLine:       statusLine,
 		WeekValues:       intervals,
+		WeekGoals:        goals,
+		WeekFocusMinutes: weekFocus,
+		Streak:           streaks.Current,
+		LongestStreak:    streaks.Longest,
+		ConfigError:      configErr,
+		CurrentTask:      currentTask,
+		Interruptions:    marks,
+	}
+}
+
+// formatRemaining renders MM:SS, switching to H:MM:SS for an hour or more.
+func formatRemaining(d time.Duration) string {
+	total := int(d.Seconds())
+	h, m, s := total/3600, (total/60)%60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
 	}
+	return fmt.Sprintf("%02d:%02d", m, s)
 }
 
 func (d *Daemon) Timer() *timer.Timer {
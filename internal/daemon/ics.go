@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/philleif/pomme/internal/export"
+)
+
+// serveICS (re)starts the local calendar feed on port, or stops it when port
+// is zero. Only loopback is bound: the feed is for calendar apps on this
+// machine.
+func (d *Daemon) serveICS(port int) error {
+	d.mu.Lock()
+	old := d.icsServer
+	d.icsServer = nil
+	d.mu.Unlock()
+
+	if old != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		old.Shutdown(ctx)
+		cancel()
+	}
+	if port == 0 {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return fmt.Errorf("ics feed: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", d.handleICS)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	d.mu.Lock()
+	d.icsServer = srv
+	d.mu.Unlock()
+
+	go srv.Serve(listener)
+	return nil
+}
+
+// handleICS serves completed sessions as an iCalendar feed. Optional from and
+// to query parameters (YYYY-MM-DD) limit the range.
+func (d *Daemon) handleICS(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" && r.URL.Path != "/pomme.ics" {
+		http.NotFound(w, r)
+		return
+	}
+
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	for _, date := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			http.Error(w, "from and to must be YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+	}
+	if err := export.CheckRange(from, to); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Buffer the feed so a failed query is a 500, not a truncated calendar.
+	var buf bytes.Buffer
+	if err := d.writeICS(&buf, from, to); err != nil {
+		http.Error(w, "failed to read sessions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+func (d *Daemon) writeICS(w io.Writer, from, to string) error {
+	iw, err := export.NewWriter(w, export.FormatICS, export.Options{WorkDuration: d.Config().WorkDurationTime()})
+	if err != nil {
+		return err
+	}
+	if err := d.storage.Sessions(from, to, iw.Write); err != nil {
+		return err
+	}
+	return iw.Close()
+}
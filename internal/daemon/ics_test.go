@@ -0,0 +1,26 @@
+package daemon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandleICS(t *testing.T) {
+	d := testDaemon(t, &fakeRunner{})
+
+	rec := httptest.NewRecorder()
+	d.handleICS(rec, httptest.NewRequest("GET", "/pomme.ics", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "BEGIN:VCALENDAR") {
+		t.Fatalf("feed = %d %q, want 200 and a calendar", rec.Code, rec.Body.String())
+	}
+
+	// A database error is a 500, not an empty or truncated feed.
+	d.storage.Close()
+	rec = httptest.NewRecorder()
+	d.handleICS(rec, httptest.NewRequest("GET", "/pomme.ics", nil))
+	if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "VCALENDAR") {
+		t.Errorf("feed with a closed database = %d %q, want a 500 without a calendar", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,66 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/philleif/pomme/internal/journal"
+)
+
+const journalStateKey = "journal_appended"
+
+// journalRetry is how long checkJournal waits after a failed append before
+// trying again.
+const journalRetry = 5 * time.Minute
+
+// checkJournal appends today's section to journal_file once journal_time has
+// passed. The last date appended is stored, so restarts don't repeat it; a
+// failed append is logged and retried.
+func (d *Daemon) checkJournal() {
+	cfg := d.Config()
+	if cfg.JournalFile == "" {
+		return
+	}
+
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	if now.Format("15:04") < cfg.JournalTime {
+		return
+	}
+
+	d.mu.Lock()
+	if d.journalDate == today || now.Before(d.journalRetryAt) {
+		d.mu.Unlock()
+		return
+	}
+	d.journalRetryAt = now.Add(journalRetry)
+	d.mu.Unlock()
+
+	if err := d.appendJournal(cfg.JournalFile, today); err != nil {
+		fmt.Fprintf(os.Stderr, "journal: %v (retrying in %v)\n", err, journalRetry)
+		return
+	}
+
+	d.mu.Lock()
+	d.journalDate = today
+	d.mu.Unlock()
+	d.storage.SetState(journalStateKey, today)
+}
+
+func (d *Daemon) appendJournal(path, date string) error {
+	cfg := d.Config()
+	format, err := journal.ParseFormat(cfg.JournalFormat)
+	if err != nil {
+		return err
+	}
+	tmpl, err := journal.Template(format, cfg.JournalTemplate)
+	if err != nil {
+		return err
+	}
+	day, err := journal.Load(d.storage, date, d.goalPlan().On(date))
+	if err != nil {
+		return err
+	}
+	return journal.Append(path, tmpl, day)
+}
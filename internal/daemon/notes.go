@@ -0,0 +1,226 @@
+package daemon
+
+import (
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/config"
+	"github.com/philleif/pomme/internal/sparkline"
+	"github.com/philleif/pomme/internal/storage"
+	"github.com/philleif/pomme/internal/timer"
+)
+
+type NoteData struct {
+	// Target is "current" when the note waits for the running work interval
+	// to complete, or "last" when it was attached to the latest one.
+	Target     string   `json:"target"`
+	IntervalID int64    `json:"interval_id,omitempty"`
+	Tags       []string `json:"tags"`
+}
+
+type StatsData struct {
+	Tag        string                `json:"tag,omitempty"`
+	Today      int                   `json:"today"`
+	DailyGoal  int                   `json:"daily_goal"`
+	DayOff     bool                  `json:"day_off,omitempty"`
+	WeekValues []int                 `json:"week_values"`
+	WeekGoals  []int                 `json:"week_goals"`
+	Sparkline  string                `json:"sparkline"`
+	WeekTags   []storage.TagCount    `json:"week_tags"`
+	TodayNotes []storage.SessionNote `json:"today_notes"`
+
+	WeekInterruptions storage.InterruptionStats `json:"week_interruptions"`
+	Streaks           storage.Streaks           `json:"streaks"`
+}
+
+// handleNote attaches a note and tags to the work interval in progress, or
+// to the last completed one when no work interval is running.
+func (d *Daemon) handleNote(cmd Command) Response {
+	note := strings.TrimSpace(cmd.Params["note"])
+	var tags []string
+	for _, tag := range strings.Split(cmd.Params["tags"], ",") {
+		if tag = storage.NormalizeTag(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	if note == "" && len(tags) == 0 {
+		return Response{Success: false, Error: "note or tags required"}
+	}
+	allTags := append(append([]string{}, tags...), storage.ParseTags(note)...)
+
+	if d.timer.Phase() == timer.PhaseWork && d.timer.State() != timer.StateIdle {
+		d.mu.Lock()
+		// Several notes during one interval all make it, joined the way
+		// AnnotateInterval joins notes on a completed one.
+		switch {
+		case note == "":
+		case d.pendingNote == "":
+			d.pendingNote = note
+		default:
+			d.pendingNote += "; " + note
+		}
+		d.pendingTags = append(d.pendingTags, tags...)
+		d.mu.Unlock()
+		return Response{Success: true, Data: NoteData{Target: "current", Tags: allTags}}
+	}
+
+	id, err := d.storage.LastIntervalID()
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+	if err := d.storage.AnnotateInterval(id, note, tags); err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+	return Response{Success: true, Data: NoteData{Target: "last", IntervalID: id, Tags: allTags}}
+}
+
+// handleInterrupt logs an internal or external interruption of the work
+// interval in progress.
+func (d *Daemon) handleInterrupt(cmd Command) Response {
+	if d.timer.Phase() != timer.PhaseWork || d.timer.State() == timer.StateIdle {
+		return Response{Success: false, Error: "no work interval in progress"}
+	}
+
+	kind := cmd.Params["kind"]
+	if kind == "" {
+		kind = storage.InterruptionInternal
+	}
+	interruption, err := d.storage.RecordInterruption(kind, strings.TrimSpace(cmd.Params["note"]))
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+	d.notifyStatusChange()
+	return Response{Success: true, Data: interruption}
+}
+
+func (d *Daemon) applyPendingNote(intervalID int64) {
+	d.mu.Lock()
+	note, tags := d.pendingNote, d.pendingTags
+	d.pendingNote, d.pendingTags = "", nil
+	d.mu.Unlock()
+
+	if note != "" || len(tags) > 0 {
+		d.storage.AnnotateInterval(intervalID, note, tags)
+	}
+}
+
+func (d *Daemon) clearPendingNote() {
+	d.mu.Lock()
+	d.pendingNote, d.pendingTags = "", nil
+	d.mu.Unlock()
+}
+
+// Stats returns today's count and the last seven days, optionally limited
+// to intervals tagged with tag.
+func (d *Daemon) Stats(tag string) (StatsData, error) {
+	tag = storage.NormalizeTag(tag)
+
+	days, err := d.storage.Last7DaysTagged(tag)
+	if err != nil {
+		return StatsData{}, err
+	}
+	values := make([]int, len(days))
+	for i, day := range days {
+		values[i] = day.Intervals
+	}
+
+	today := time.Now().Format("2006-01-02")
+	weekStart := time.Now().AddDate(0, 0, -6).Format("2006-01-02")
+	weekTags, err := d.storage.TagCounts(weekStart, today)
+	if err != nil {
+		return StatsData{}, err
+	}
+	notes, err := d.storage.Notes(today, today)
+	if err != nil {
+		return StatsData{}, err
+	}
+	interruptions, err := d.storage.InterruptionCounts(weekStart, today)
+	if err != nil {
+		return StatsData{}, err
+	}
+
+	streaks, err := d.streaks(4)
+	if err != nil {
+		return StatsData{}, err
+	}
+
+	plan := d.goalPlan()
+	goals := plan.Goals(time.Now().AddDate(0, 0, -6), len(values))
+	goal := plan.On(today)
+	return StatsData{
+		Tag:        tag,
+		Today:      values[len(values)-1],
+		DailyGoal:  goal.Goal,
+		DayOff:     goal.Off,
+		WeekValues: values,
+		WeekGoals:  goals,
+		Sparkline:  sparkline.GenerateBrailleSpacedGoals(values, goals),
+		WeekTags:   weekTags,
+		TodayNotes: notes,
+
+		WeekInterruptions: interruptions,
+		Streaks:           streaks,
+	}, nil
+}
+
+// streaks computes goal streaks, with hit rates for the last weeks ISO weeks.
+func (d *Daemon) streaks(weeks int) (storage.Streaks, error) {
+	return d.storage.Streaks(time.Now(), d.goalPlan(), weeks)
+}
+
+// currentStreaks is streaks(0) for the status, which is asked for several
+// times a second while a timer runs, so it is cached until the day changes or
+// invalidateStreaks is called.
+func (d *Daemon) currentStreaks() storage.Streaks {
+	today := time.Now().Format("2006-01-02")
+	d.mu.RLock()
+	cached, day, gen := d.streak, d.streakDay, d.streakGen
+	d.mu.RUnlock()
+	if day == today {
+		return cached
+	}
+
+	streaks, err := d.streaks(0)
+	if err != nil {
+		return storage.Streaks{}
+	}
+	d.mu.Lock()
+	// Don't cache a result computed before an invalidation.
+	if d.streakGen == gen {
+		d.streak, d.streakDay = streaks, today
+	}
+	d.mu.Unlock()
+	return streaks
+}
+
+// invalidateStreaks drops the cached streak after the history or the goals
+// change.
+func (d *Daemon) invalidateStreaks() {
+	d.mu.Lock()
+	d.streakDay = ""
+	d.streakGen++
+	d.mu.Unlock()
+}
+
+// InvalidateStats refreshes what the daemon keeps from the history after
+// sessions are written outside it, as by pomme import.
+func (d *Daemon) InvalidateStats() {
+	d.invalidateStreaks()
+	if n, err := d.storage.TodayCount(); err == nil {
+		d.timer.SetIntervalsToday(n)
+	}
+	d.notifyStatusChange()
+}
+
+// goalPlan decides each day's goal from the config and the goal history.
+// Without a readable history every day uses the current config.
+func (d *Daemon) goalPlan() storage.GoalPlan {
+	cfg := d.Config()
+	plan, _ := d.storage.GoalPlan(goalChange(cfg, time.Now().Format("2006-01-02")), cfg.GoalOverrides)
+	return plan
+}
+
+// goalChange is the goal cfg sets from date on.
+func goalChange(cfg config.Config, date string) storage.GoalChange {
+	return storage.GoalChange{From: date, Goal: cfg.DailyGoal, Weekdays: cfg.WeekdayGoals, RestDays: cfg.RestDays}
+}
@@ -0,0 +1,33 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+// Sessions written outside the daemon, as by pomme import, show up in the
+// status once InvalidateStats is called.
+func TestInvalidateStats(t *testing.T) {
+	d := testDaemon(t, &fakeRunner{})
+	d.config.DailyGoal = 1
+
+	if got := d.currentStreaks().Current; got != 0 {
+		t.Fatalf("streak before = %d, want 0", got)
+	}
+	if _, err := d.storage.RecordInterval(storage.Interval{StartedAt: time.Now().Add(-25 * time.Minute), Duration: 25 * time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	if got := d.currentStreaks().Current; got != 0 {
+		t.Fatalf("streak before invalidating = %d, want the cached 0", got)
+	}
+
+	d.InvalidateStats()
+	if got := d.currentStreaks().Current; got != 1 {
+		t.Errorf("streak = %d, want 1", got)
+	}
+	if got := d.timer.IntervalsToday(); got != 1 {
+		t.Errorf("intervals today = %d, want 1", got)
+	}
+}
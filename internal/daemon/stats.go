@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/philleif/pomme/internal/sparkline"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+// handleStatsQuery answers a storage.StatsQuery. Params: by (default day),
+// from, to, tag, task and fill ("true").
+func (d *Daemon) handleStatsQuery(cmd Command) Response {
+	by := cmd.Params["by"]
+	if by == "" {
+		by = string(storage.GroupDay)
+	}
+	groupBy, err := storage.ParseGroupBy(by)
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+
+	q := storage.StatsQuery{
+		From:    cmd.Params["from"],
+		To:      cmd.Params["to"],
+		GroupBy: groupBy,
+		Tag:     cmd.Params["tag"],
+		Fill:    cmd.Params["fill"] == "true",
+	}
+	if v := cmd.Params["task"]; v != "" {
+		if q.TaskID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return Response{Success: false, Error: fmt.Sprintf("invalid task id %q", v)}
+		}
+	}
+
+	buckets, err := d.storage.StatsQuery(q)
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+	if buckets == nil {
+		buckets = []storage.Bucket{}
+	}
+	return Response{Success: true, Data: buckets}
+}
+
+// handleCalendar answers Calendar. Params: weeks (default 53).
+func (d *Daemon) handleCalendar(cmd Command) Response {
+	weeks := 53
+	if v := cmd.Params["weeks"]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 520 {
+			return Response{Success: false, Error: fmt.Sprintf("invalid number of weeks %q", v)}
+		}
+		weeks = n
+	}
+	days, err := d.Calendar(weeks)
+	if err != nil {
+		return Response{Success: false, Error: err.Error()}
+	}
+	return Response{Success: true, Data: days}
+}
+
+// Calendar returns every day of the last weeks ISO weeks up to today, Monday
+// first, with each day's goal. Days off use the daily goal so work done on
+// them still shades relative to a normal day.
+func (d *Daemon) Calendar(weeks int) ([]sparkline.CalendarDay, error) {
+	now := time.Now()
+	monday := now.AddDate(0, 0, -((int(now.Weekday()) + 6) % 7))
+	start := monday.AddDate(0, 0, -7*(weeks-1))
+
+	buckets, err := d.storage.StatsQuery(storage.StatsQuery{
+		From:    start.Format("2006-01-02"),
+		To:      now.Format("2006-01-02"),
+		GroupBy: storage.GroupDay,
+		Fill:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	plan := d.goalPlan()
+	fallback := d.Config().DailyGoal
+	days := make([]sparkline.CalendarDay, len(buckets))
+	for i, b := range buckets {
+		goal := plan.On(b.Key).Goal
+		if goal == 0 {
+			goal = fallback
+		}
+		days[i] = sparkline.CalendarDay{Date: b.Key, Count: b.Count, Goal: goal}
+	}
+	return days, nil
+}
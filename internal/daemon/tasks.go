@@ -0,0 +1,146 @@
+package daemon
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func (d *Daemon) handleTaskCommand(cmd Command) Response {
+	switch cmd.Action {
+	case "task_add":
+		estimate := 0
+		if v := cmd.Params["estimate"]; v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return Response{Success: false, Error: fmt.Sprintf("invalid estimate %q", v)}
+			}
+			estimate = n
+		}
+		task, err := d.storage.AddTask(cmd.Params["title"], cmd.Params["project"], estimate)
+		if err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		if cmd.Params["select"] == "true" {
+			if err := d.SelectTask(task.ID); err != nil {
+				return Response{Success: false, Error: err.Error()}
+			}
+		}
+		return Response{Success: true, Data: task}
+
+	case "task_list":
+		tasks, err := d.storage.Tasks(cmd.Params["all"] == "true")
+		if err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		if tasks == nil {
+			tasks = []storage.Task{}
+		}
+		return Response{Success: true, Data: tasks}
+
+	case "task_done":
+		id, err := d.taskIDParam(cmd)
+		if err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		if id == 0 {
+			return Response{Success: false, Error: "no task selected"}
+		}
+		task, err := d.storage.CompleteTask(id)
+		if err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		if id == d.currentTaskID() {
+			d.SelectTask(0)
+		}
+		return Response{Success: true, Data: task}
+
+	case "task_select":
+		id, err := d.taskIDParam(cmd)
+		if err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		if err := d.SelectTask(id); err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		return Response{Success: true, Data: d.GetStatus()}
+
+	case "task_pick":
+		if _, err := d.PickTask(cmd.Params["ref"]); err != nil {
+			return Response{Success: false, Error: err.Error()}
+		}
+		return Response{Success: true, Data: d.GetStatus()}
+	}
+
+	return Response{Success: false, Error: "unknown action"}
+}
+
+// taskIDParam reads the "id" param, defaulting to the current task.
+func (d *Daemon) taskIDParam(cmd Command) (int64, error) {
+	v := cmd.Params["id"]
+	if v == "" {
+		return d.currentTaskID(), nil
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid task id %q", v)
+	}
+	return id, nil
+}
+
+// SelectTask makes id the current task; work intervals completed from now
+// on are linked to it. Zero clears the selection.
+func (d *Daemon) SelectTask(id int64) error {
+	var current *storage.Task
+	if id != 0 {
+		task, err := d.storage.Task(id)
+		if err != nil {
+			return err
+		}
+		if task.Status != storage.TaskActive {
+			return fmt.Errorf("task %d is already done", id)
+		}
+		current = &task
+	}
+
+	if err := d.storage.SetCurrentTaskID(id); err != nil {
+		return err
+	}
+
+	previous := d.currentTaskID()
+	d.mu.Lock()
+	d.currentTask = current
+	d.mu.Unlock()
+
+	if previous != id {
+		d.retrack()
+	}
+	d.notifyStatusChange()
+	return nil
+}
+
+func (d *Daemon) currentTaskID() int64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.currentTask == nil {
+		return 0
+	}
+	return d.currentTask.ID
+}
+
+// refreshCurrentTask reloads the current task so its completed count stays
+// in step with recorded intervals.
+func (d *Daemon) refreshCurrentTask() {
+	id := d.currentTaskID()
+	if id == 0 {
+		return
+	}
+	task, err := d.storage.Task(id)
+	if err != nil {
+		return
+	}
+	d.mu.Lock()
+	d.currentTask = &task
+	d.mu.Unlock()
+}
@@ -0,0 +1,90 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+// setInInterval tells the blocker whether a work interval is running and,
+// with timewarrior_enabled, starts or stops a matching Timewarrior interval.
+func (d *Daemon) setInInterval(in bool) {
+	d.blocker.SetInInterval(in)
+
+	d.mu.Lock()
+	track := in && d.config.TimewarriorEnabled
+	changed := track != d.tracking
+	d.tracking = track
+	d.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if track {
+		d.startTracking()
+	} else {
+		d.logWarrior(d.timew.Stop())
+	}
+}
+
+// startTracking starts a Timewarrior interval tagged for the current task.
+func (d *Daemon) startTracking() {
+	d.mu.RLock()
+	task := d.currentTask
+	d.mu.RUnlock()
+
+	d.logWarrior(d.timew.Start(d.timewTags(task)))
+}
+
+// retrack restarts the Timewarrior interval after the current task changed
+// mid-interval, so the time before and after is tagged correctly.
+func (d *Daemon) retrack() {
+	d.mu.RLock()
+	tracking := d.tracking
+	d.mu.RUnlock()
+	if tracking {
+		d.startTracking()
+	}
+}
+
+// timewTags tags an interval like Timewarrior's Taskwarrior hook does. Tasks
+// not picked from Taskwarrior use their title and project.
+func (d *Daemon) timewTags(task *storage.Task) []string {
+	if task == nil {
+		return nil
+	}
+	if task.TaskwarriorUUID != "" {
+		if tw, err := d.taskw.Get(task.TaskwarriorUUID); err == nil {
+			return tw.TimewTags()
+		}
+	}
+	tags := []string{task.Title}
+	if task.Project != "" {
+		tags = append(tags, task.Project)
+	}
+	return tags
+}
+
+// PickTask makes the Taskwarrior task with the given ID or UUID the current
+// task, creating or updating the pomme task linked to it.
+func (d *Daemon) PickTask(ref string) (storage.Task, error) {
+	tw, err := d.taskw.Get(ref)
+	if err != nil {
+		return storage.Task{}, err
+	}
+	task, err := d.storage.TaskwarriorTask(tw.UUID, tw.Description, tw.Project)
+	if err != nil {
+		return storage.Task{}, err
+	}
+	if err := d.SelectTask(task.ID); err != nil {
+		return storage.Task{}, err
+	}
+	return task, nil
+}
+
+func (d *Daemon) logWarrior(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "timewarrior: %v\n", err)
+	}
+}
@@ -0,0 +1,154 @@
+package daemon
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/philleif/pomme/internal/blocker"
+	"github.com/philleif/pomme/internal/config"
+	"github.com/philleif/pomme/internal/storage"
+	"github.com/philleif/pomme/internal/timer"
+	"github.com/philleif/pomme/internal/warrior"
+)
+
+// fakeRunner records timew and task commands; task export answers with
+// output.
+type fakeRunner struct {
+	calls  []string
+	output string
+}
+
+func (f *fakeRunner) Run(name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, name+" "+strings.Join(args, " "))
+	if name == "task" {
+		return []byte(f.output), nil
+	}
+	return nil, nil
+}
+
+// timewCalls returns the timew commands run since the last call.
+func (f *fakeRunner) timewCalls() []string {
+	var calls []string
+	for _, call := range f.calls {
+		if strings.HasPrefix(call, "timew ") {
+			calls = append(calls, call)
+		}
+	}
+	f.calls = nil
+	return calls
+}
+
+func testDaemon(t *testing.T, r warrior.Runner) *Daemon {
+	t.Helper()
+	store, err := storage.Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	cfg := config.Default()
+	cfg.TimewarriorEnabled = true
+	return &Daemon{
+		config:  cfg,
+		timer:   timer.New(timerConfig(cfg)),
+		storage: store,
+		blocker: blocker.New(),
+		timew:   &warrior.Timewarrior{Runner: r},
+		taskw:   &warrior.Taskwarrior{Runner: r},
+	}
+}
+
+func TestRetrack(t *testing.T) {
+	r := &fakeRunner{}
+	d := testDaemon(t, r)
+	write, err := d.storage.AddTask("Write docs", "pomme", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	review, err := d.storage.AddTask("Review", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Picking a task outside a work interval tracks nothing.
+	if err := d.SelectTask(write.ID); err != nil {
+		t.Fatal(err)
+	}
+	if calls := r.timewCalls(); len(calls) != 0 {
+		t.Errorf("selecting while idle ran %q", calls)
+	}
+
+	d.setInInterval(true)
+	if want := []string{"timew start Write docs pomme :quiet"}; !reflect.DeepEqual(r.timewCalls(), want) {
+		t.Errorf("starting didn't run %q", want)
+	}
+	d.setInInterval(true)
+	if calls := r.timewCalls(); len(calls) != 0 {
+		t.Errorf("staying in the interval ran %q", calls)
+	}
+
+	// Switching mid-interval starts a new Timewarrior interval, which ends
+	// the open one.
+	if err := d.SelectTask(review.ID); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"timew start Review :quiet"}; !reflect.DeepEqual(r.timewCalls(), want) {
+		t.Errorf("switching didn't run %q", want)
+	}
+	if err := d.SelectTask(review.ID); err != nil {
+		t.Fatal(err)
+	}
+	if calls := r.timewCalls(); len(calls) != 0 {
+		t.Errorf("reselecting the same task ran %q", calls)
+	}
+	if err := d.SelectTask(0); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"timew start :quiet"}; !reflect.DeepEqual(r.timewCalls(), want) {
+		t.Errorf("clearing the task didn't run %q", want)
+	}
+
+	d.setInInterval(false)
+	if want := []string{"timew stop :quiet"}; !reflect.DeepEqual(r.timewCalls(), want) {
+		t.Errorf("stopping didn't run %q", want)
+	}
+	if err := d.SelectTask(write.ID); err != nil {
+		t.Fatal(err)
+	}
+	if calls := r.timewCalls(); len(calls) != 0 {
+		t.Errorf("selecting after the interval ran %q", calls)
+	}
+}
+
+func TestRetrackTaskwarriorTask(t *testing.T) {
+	r := &fakeRunner{output: `[{"id": 7, "uuid": "b2", "description": "Fix bug", "project": "pomme", "tags": ["oncall"], "status": "pending"}]`}
+	d := testDaemon(t, r)
+	d.setInInterval(true)
+	r.timewCalls()
+
+	task, err := d.PickTask("7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.TaskwarriorUUID != "b2" || task.Title != "Fix bug" {
+		t.Errorf("picked %+v", task)
+	}
+	// Tagged like Timewarrior's own hook, looked up again by UUID.
+	if want := []string{"timew start Fix bug pomme oncall :quiet"}; !reflect.DeepEqual(r.timewCalls(), want) {
+		t.Errorf("picking didn't run %q", want)
+	}
+}
+
+func TestTrackingDisabled(t *testing.T) {
+	r := &fakeRunner{}
+	d := testDaemon(t, r)
+	d.config.TimewarriorEnabled = false
+
+	d.setInInterval(true)
+	d.retrack()
+	d.setInInterval(false)
+	if len(r.calls) != 0 {
+		t.Errorf("ran %q with timewarrior_enabled off", r.calls)
+	}
+}
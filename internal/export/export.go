@@ -0,0 +1,170 @@
+// Package export writes session history in stable, documented formats.
+//
+// Every tabular format carries the fields of storage.Session. CSV uses the
+// column order in CSVHeader with tags joined by ";" and an empty task_id for
+// sessions without a task; it has interruption counts, while JSON and JSONL
+// also list each interruption's kind, time and note. New fields are only
+// ever appended. The ics format
+// writes one VEVENT per session for calendar overlays.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSON  Format = "json"
+	FormatJSONL Format = "jsonl"
+	FormatICS   Format = "ics"
+)
+
+var CSVHeader = []string{
+	"id", "date", "started_at", "completed_at", "duration_seconds",
+	"task_id", "task", "project", "note", "tags",
+	"interruptions_internal", "interruptions_external",
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatCSV, FormatJSON, FormatJSONL, FormatICS:
+		return f, nil
+	case "ndjson":
+		return FormatJSONL, nil
+	case "ical":
+		return FormatICS, nil
+	}
+	return "", fmt.Errorf("unknown format %q (use csv, json, jsonl or ics)", s)
+}
+
+// CheckRange rejects a range of dates (YYYY-MM-DD, either may be empty for
+// unbounded) that ends before it starts.
+func CheckRange(from, to string) error {
+	if from == "" || to == "" {
+		return nil
+	}
+	start, err1 := time.Parse("2006-01-02", from)
+	end, err2 := time.Parse("2006-01-02", to)
+	if err1 != nil || err2 != nil {
+		return fmt.Errorf("invalid range %s..%s (use YYYY-MM-DD)", from, to)
+	}
+	if end.Before(start) {
+		return fmt.Errorf("range ends before it starts: %s..%s", from, to)
+	}
+	return nil
+}
+
+// Writer streams sessions in one format. Call Write for each session and
+// Close once at the end.
+type Writer interface {
+	Write(storage.Session) error
+	Close() error
+}
+
+// Options adjust how a Writer writes sessions.
+type Options struct {
+	// WorkDuration is how long ics events for sessions recorded without a
+	// start or duration last, ending when they completed. Zero leaves them
+	// zero-length.
+	WorkDuration time.Duration
+}
+
+func NewWriter(w io.Writer, format Format, opts Options) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(CSVHeader); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: cw}, nil
+	case FormatJSON:
+		return &jsonWriter{w: w}, nil
+	case FormatJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case FormatICS:
+		return newICSWriter(w, opts.WorkDuration), nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Write(s storage.Session) error {
+	taskID := ""
+	if s.TaskID != nil {
+		taskID = strconv.FormatInt(*s.TaskID, 10)
+	}
+	return c.w.Write([]string{
+		strconv.FormatInt(s.ID, 10),
+		s.Date,
+		s.StartedAt,
+		s.CompletedAt,
+		strconv.Itoa(s.DurationSeconds),
+		taskID,
+		s.Task,
+		s.Project,
+		s.Note,
+		strings.Join(s.Tags, ";"),
+		strconv.Itoa(s.InterruptionsInternal),
+		strconv.Itoa(s.InterruptionsExternal),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter emits a single array, one session per line, without holding
+// the history in memory.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) Write(s storage.Session) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	sep := ",\n  "
+	if j.count == 0 {
+		sep = "[\n  "
+	}
+	j.count++
+	_, err = fmt.Fprintf(j.w, "%s%s", sep, data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.count == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) Write(s storage.Session) error {
+	return j.enc.Encode(s)
+}
+
+func (j *jsonlWriter) Close() error {
+	return nil
+}
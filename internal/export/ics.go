@@ -0,0 +1,120 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// icsWriter emits each session as a VEVENT in a single VCALENDAR.
+type icsWriter struct {
+	w   io.Writer
+	err error
+
+	// fallback is the length of sessions with no start or duration.
+	fallback time.Duration
+}
+
+func newICSWriter(w io.Writer, fallback time.Duration) *icsWriter {
+	iw := &icsWriter{w: w, fallback: fallback}
+	iw.line("BEGIN:VCALENDAR")
+	iw.line("VERSION:2.0")
+	iw.line("PRODID:-//Pomme//Focus Sessions//EN")
+	iw.line("CALSCALE:GREGORIAN")
+	iw.line("X-WR-CALNAME:Pomme focus")
+	return iw
+}
+
+func (iw *icsWriter) Write(s storage.Session) error {
+	end, err := time.Parse(time.RFC3339, s.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("session %d: %w", s.ID, err)
+	}
+	start := end.Add(-time.Duration(s.DurationSeconds) * time.Second)
+	if t, err := time.Parse(time.RFC3339, s.StartedAt); err == nil {
+		start = t
+	} else if s.DurationSeconds == 0 {
+		// Sessions from before starts were recorded would otherwise be
+		// invisible in a calendar.
+		start = end.Add(-iw.fallback)
+	}
+
+	iw.line("BEGIN:VEVENT")
+	iw.line(fmt.Sprintf("UID:session-%d@pomme", s.ID))
+	iw.line("DTSTAMP:" + end.UTC().Format(icsTimeFormat))
+	iw.line("DTSTART:" + start.UTC().Format(icsTimeFormat))
+	iw.line("DTEND:" + end.UTC().Format(icsTimeFormat))
+	iw.line("SUMMARY:" + icsEscape(icsSummary(s)))
+	if desc := icsDescription(s); desc != "" {
+		iw.line("DESCRIPTION:" + icsEscape(desc))
+	}
+	categories := []string{"Pomodoro", "Work"}
+	for _, tag := range s.Tags {
+		categories = append(categories, icsEscape(tag))
+	}
+	iw.line("CATEGORIES:" + strings.Join(categories, ","))
+	iw.line("TRANSP:OPAQUE")
+	iw.line("END:VEVENT")
+	return iw.err
+}
+
+func (iw *icsWriter) Close() error {
+	iw.line("END:VCALENDAR")
+	return iw.err
+}
+
+// line writes a content line folded at 75 octets, as RFC 5545 requires.
+func (iw *icsWriter) line(s string) {
+	if iw.err != nil {
+		return
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		n := len(string(r))
+		if width+n > 75 {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += n
+	}
+	b.WriteString("\r\n")
+	_, iw.err = io.WriteString(iw.w, b.String())
+}
+
+func icsSummary(s storage.Session) string {
+	switch {
+	case s.Task != "":
+		return "🍅 " + s.Task
+	case len(s.Tags) > 0:
+		return "🍅 #" + strings.Join(s.Tags, " #")
+	default:
+		return "🍅 Focus"
+	}
+}
+
+func icsDescription(s storage.Session) string {
+	var parts []string
+	if s.Note != "" {
+		parts = append(parts, s.Note)
+	}
+	if s.Project != "" {
+		parts = append(parts, "Project: "+s.Project)
+	}
+	if n := s.InterruptionsInternal + s.InterruptionsExternal; n > 0 {
+		parts = append(parts, fmt.Sprintf("Interruptions: %d internal, %d external",
+			s.InterruptionsInternal, s.InterruptionsExternal))
+	}
+	return strings.Join(parts, "\n")
+}
+
+func icsEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return r.Replace(s)
+}
@@ -0,0 +1,169 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+// Fields a CSV column can be mapped to. They match pomme's export columns.
+var mappableFields = []string{
+	"started_at", "completed_at", "duration_seconds",
+	"task", "project", "note", "tags",
+	"interruptions_internal", "interruptions_external",
+}
+
+// Mapping maps pomme fields to CSV column names. Fields not in the mapping
+// are read from a column of the same name, if there is one.
+type Mapping map[string]string
+
+// ParseMapping parses "field=Column,field=Column", e.g.
+// "started_at=Start,completed_at=End,task=Title".
+func ParseMapping(s string) (Mapping, error) {
+	m := make(Mapping)
+	if strings.TrimSpace(s) == "" {
+		return m, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field = strings.TrimSpace(field)
+		if !ok || strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("invalid mapping %q (use field=Column)", pair)
+		}
+		if !isMappable(field) {
+			return nil, fmt.Errorf("unknown field %q (use one of %s)", field, strings.Join(mappableFields, ", "))
+		}
+		m[field] = strings.TrimSpace(column)
+	}
+	return m, nil
+}
+
+func isMappable(field string) bool {
+	for _, f := range mappableFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// headerIndex maps lower-cased column names to their position, ignoring a
+// byte order mark as written by spreadsheet apps.
+func headerIndex(header []string) map[string]int {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	return index
+}
+
+// readCSV reads a CSV with a header row. pomme's own CSV export needs no
+// mapping.
+func readCSV(r io.Reader, mapping Mapping) ([]storage.Session, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := headerIndex(header)
+	columns := make(map[string]int)
+	for _, field := range mappableFields {
+		name := field
+		if column, ok := mapping[field]; ok {
+			name = column
+		}
+		i, ok := index[strings.ToLower(name)]
+		if !ok {
+			if _, mapped := mapping[field]; mapped {
+				return nil, fmt.Errorf("column %q not found in header", name)
+			}
+			continue
+		}
+		columns[field] = i
+	}
+	if _, ok := columns["completed_at"]; !ok {
+		if _, ok := columns["started_at"]; !ok {
+			return nil, errors.New("no completed_at or started_at column; map one with --map")
+		}
+	}
+
+	var sessions []storage.Session
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		get := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		sess, err := csvSession(get)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, nil
+}
+
+func csvSession(get func(string) string) (storage.Session, error) {
+	sess := storage.Session{
+		Task:    get("task"),
+		Project: get("project"),
+		Note:    get("note"),
+		Tags:    splitList(get("tags")),
+	}
+
+	var start, end time.Time
+	var seconds int
+	var err error
+	if v := get("started_at"); v != "" {
+		if start, err = parseTime(v); err != nil {
+			return sess, fmt.Errorf("started_at: %w", err)
+		}
+	}
+	if v := get("completed_at"); v != "" {
+		if end, err = parseTime(v); err != nil {
+			return sess, fmt.Errorf("completed_at: %w", err)
+		}
+	}
+	if v := get("duration_seconds"); v != "" {
+		if seconds, err = parseSeconds(v); err != nil {
+			return sess, fmt.Errorf("duration_seconds: %w", err)
+		}
+	}
+	for field, dst := range map[string]*int{
+		"interruptions_internal": &sess.InterruptionsInternal,
+		"interruptions_external": &sess.InterruptionsExternal,
+	} {
+		if v := get(field); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return sess, fmt.Errorf("%s: expected a count, got %q", field, v)
+			}
+			*dst = n
+		}
+	}
+
+	return sess, complete(&sess, start, end, seconds)
+}
@@ -0,0 +1,187 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+// readPomme reads `pomme export` output in any of its tabular formats,
+// detected from the first byte: a JSON array, JSON Lines or CSV.
+func readPomme(r io.Reader) ([]storage.Session, error) {
+	br := bufio.NewReader(r)
+	first, err := firstByte(br)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch first {
+	case '[':
+		var sessions []storage.Session
+		if err := json.NewDecoder(br).Decode(&sessions); err != nil {
+			return nil, err
+		}
+		return checkPomme(sessions)
+	case '{':
+		var sessions []storage.Session
+		dec := json.NewDecoder(br)
+		for {
+			var sess storage.Session
+			err := dec.Decode(&sess)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("record %d: %w", len(sessions)+1, err)
+			}
+			sessions = append(sessions, sess)
+		}
+		return checkPomme(sessions)
+	}
+	return readCSV(br, nil)
+}
+
+func firstByte(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.ContainsAny(b, " \t\r\n") {
+			return b[0], nil
+		}
+		br.ReadByte()
+	}
+}
+
+func checkPomme(sessions []storage.Session) ([]storage.Session, error) {
+	for i, sess := range sessions {
+		if _, err := time.Parse(time.RFC3339, sess.CompletedAt); err != nil {
+			return nil, fmt.Errorf("record %d: invalid completed_at %q", i+1, sess.CompletedAt)
+		}
+	}
+	return sessions, nil
+}
+
+type timewInterval struct {
+	Start      string   `json:"start"`
+	End        string   `json:"end"`
+	Tags       []string `json:"tags"`
+	Annotation string   `json:"annotation"`
+}
+
+// readTimewarrior reads `timew export` output. Tags containing spaces
+// become dashed, and open intervals, still being tracked, are skipped.
+func readTimewarrior(r io.Reader) ([]storage.Session, error) {
+	var intervals []timewInterval
+	if err := json.NewDecoder(r).Decode(&intervals); err != nil {
+		return nil, err
+	}
+
+	var sessions []storage.Session
+	for i, iv := range intervals {
+		if iv.End == "" {
+			continue
+		}
+		start, err := time.Parse("20060102T150405Z", iv.Start)
+		if err != nil {
+			return nil, fmt.Errorf("interval %d: invalid start %q", i+1, iv.Start)
+		}
+		end, err := time.Parse("20060102T150405Z", iv.End)
+		if err != nil {
+			return nil, fmt.Errorf("interval %d: invalid end %q", i+1, iv.End)
+		}
+
+		sess := storage.Session{Note: iv.Annotation}
+		for _, tag := range iv.Tags {
+			sess.Tags = append(sess.Tags, strings.Join(strings.Fields(tag), "-"))
+		}
+		if err := complete(&sess, start.Local(), end.Local(), 0); err != nil {
+			return nil, fmt.Errorf("interval %d: %w", i+1, err)
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, nil
+}
+
+// readToggl reads a Toggl Track detailed report exported as CSV. The entry
+// description becomes the task, falling back to Toggl's own task column.
+func readToggl(r io.Reader) ([]storage.Session, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	index := headerIndex(header)
+	for _, required := range []string{"start date", "start time"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("column %q not found; is this a Toggl detailed report?", required)
+		}
+	}
+
+	var sessions []storage.Session
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		get := func(column string) string {
+			if i, ok := index[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		sess := storage.Session{
+			Task:    get("description"),
+			Project: get("project"),
+			Tags:    splitList(get("tags")),
+		}
+		if sess.Task == "" {
+			sess.Task = get("task")
+		}
+
+		start, err := parseTime(get("start date") + " " + get("start time"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: start: %w", line, err)
+		}
+		var end time.Time
+		if get("end date") != "" {
+			if end, err = parseTime(get("end date") + " " + get("end time")); err != nil {
+				return nil, fmt.Errorf("line %d: end: %w", line, err)
+			}
+		}
+		var seconds int
+		if v := get("duration"); v != "" {
+			if seconds, err = parseSeconds(v); err != nil {
+				return nil, fmt.Errorf("line %d: duration: %w", line, err)
+			}
+		}
+		if err := complete(&sess, start, end, seconds); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, nil
+}
@@ -0,0 +1,177 @@
+// Package importer reads session history from other tools into
+// storage.Session values for storage.ImportSessions.
+//
+// Supported formats are pomme's own export (JSON, JSON Lines or CSV), a
+// generic CSV with a column mapping, Timewarrior's `timew export` JSON and
+// Toggl Track's detailed CSV report. Every parser returns sessions with at
+// least CompletedAt set, as RFC 3339.
+package importer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+type Format string
+
+const (
+	FormatPomme       Format = "pomme"
+	FormatCSV         Format = "csv"
+	FormatTimewarrior Format = "timewarrior"
+	FormatToggl       Format = "toggl"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatPomme, FormatCSV, FormatTimewarrior, FormatToggl:
+		return f, nil
+	case "timew":
+		return FormatTimewarrior, nil
+	}
+	return "", fmt.Errorf("unknown format %q (use pomme, csv, timewarrior or toggl)", s)
+}
+
+// Read parses r in format. mapping is only used by FormatCSV; see ParseMapping.
+// Timewarrior and Toggl entries track time rather than pomodoros and may run
+// for hours, so they are split into pomodoros of about work each; see Split.
+func Read(r io.Reader, format Format, mapping Mapping, work time.Duration) ([]storage.Session, error) {
+	switch format {
+	case FormatPomme:
+		return readPomme(r)
+	case FormatCSV:
+		return readCSV(r, mapping)
+	case FormatTimewarrior:
+		sessions, err := readTimewarrior(r)
+		return Split(sessions, work), err
+	case FormatToggl:
+		sessions, err := readToggl(r)
+		return Split(sessions, work), err
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// Split divides each session with a start time into back-to-back sessions,
+// as many as work fits into it, rounded and at least one, of equal length.
+// The pieces keep the task and tags; the note goes on the last one, which
+// ends when the session did.
+func Split(sessions []storage.Session, work time.Duration) []storage.Session {
+	if work <= 0 {
+		return sessions
+	}
+	var out []storage.Session
+	for _, sess := range sessions {
+		start, err := time.Parse(time.RFC3339, sess.StartedAt)
+		length := time.Duration(sess.DurationSeconds) * time.Second
+		n := int(math.Round(float64(length) / float64(work)))
+		if err != nil || n <= 1 {
+			out = append(out, sess)
+			continue
+		}
+
+		end := sess.CompletedAt
+		for i := range n {
+			piece := sess
+			from := start.Add(length * time.Duration(i) / time.Duration(n))
+			to := start.Add(length * time.Duration(i+1) / time.Duration(n))
+			piece.StartedAt = from.Format(time.RFC3339)
+			piece.CompletedAt = to.Format(time.RFC3339)
+			piece.DurationSeconds = int(to.Sub(from).Seconds())
+			if i < n-1 {
+				piece.Note = ""
+			} else {
+				piece.CompletedAt = end
+			}
+			out = append(out, piece)
+		}
+	}
+	return out
+}
+
+// timeLayouts are tried in order for timestamps without a fixed format.
+// Layouts without a zone are read as local time.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+}
+
+func parseTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// parseSeconds accepts a number of seconds, a Go duration such as "25m" or a
+// clock duration such as "0:25:00".
+func parseSeconds(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return int(d.Seconds()), nil
+	}
+	parts := strings.Split(s, ":")
+	if len(parts) == 2 || len(parts) == 3 {
+		total := 0
+		for _, p := range parts {
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			total = total*60 + n
+		}
+		if len(parts) == 2 {
+			total *= 60
+		}
+		return total, nil
+	}
+	return 0, fmt.Errorf("invalid duration %q", s)
+}
+
+// complete fills in whichever of start, end and duration is missing and
+// stores them on sess.
+func complete(sess *storage.Session, start, end time.Time, seconds int) error {
+	switch {
+	case !end.IsZero():
+	case !start.IsZero() && seconds > 0:
+		end = start.Add(time.Duration(seconds) * time.Second)
+	default:
+		return fmt.Errorf("needs an end time, or a start time and duration")
+	}
+	if !start.IsZero() {
+		if end.Before(start) {
+			return fmt.Errorf("ends before it starts")
+		}
+		sess.StartedAt = start.Format(time.RFC3339)
+		if seconds == 0 {
+			seconds = int(end.Sub(start).Seconds())
+		}
+	}
+	sess.CompletedAt = end.Format(time.RFC3339)
+	sess.DurationSeconds = seconds
+	return nil
+}
+
+// splitList splits a tag list on commas or semicolons.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
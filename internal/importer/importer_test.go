@@ -0,0 +1,70 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func TestSplit(t *testing.T) {
+	sessions := []storage.Session{
+		// 100 minutes is four pomodoros of 25.
+		{StartedAt: "2026-03-02T09:00:00Z", CompletedAt: "2026-03-02T10:40:00Z", DurationSeconds: 6000, Task: "Write", Tags: []string{"docs"}, Note: "chapter 2"},
+		// 35 minutes rounds to one.
+		{StartedAt: "2026-03-02T11:00:00Z", CompletedAt: "2026-03-02T11:35:00Z", DurationSeconds: 2100},
+		// Without a start there is nothing to split.
+		{CompletedAt: "2026-03-02T14:00:00Z", DurationSeconds: 7200},
+	}
+	got := Split(sessions, 25*time.Minute)
+
+	var spans []string
+	for _, s := range got {
+		spans = append(spans, s.StartedAt+".."+s.CompletedAt)
+	}
+	want := []string{
+		"2026-03-02T09:00:00Z..2026-03-02T09:25:00Z",
+		"2026-03-02T09:25:00Z..2026-03-02T09:50:00Z",
+		"2026-03-02T09:50:00Z..2026-03-02T10:15:00Z",
+		"2026-03-02T10:15:00Z..2026-03-02T10:40:00Z",
+		"2026-03-02T11:00:00Z..2026-03-02T11:35:00Z",
+		"..2026-03-02T14:00:00Z",
+	}
+	if !reflect.DeepEqual(spans, want) {
+		t.Fatalf("spans:\n%s\nwant:\n%s", strings.Join(spans, "\n"), strings.Join(want, "\n"))
+	}
+	for i, s := range got[:4] {
+		if s.DurationSeconds != 1500 || s.Task != "Write" || len(s.Tags) != 1 {
+			t.Errorf("piece %d = %+v", i, s)
+		}
+		if wantNote := i == 3; (s.Note != "") != wantNote {
+			t.Errorf("piece %d note = %q", i, s.Note)
+		}
+	}
+}
+
+func TestReadTimewarriorSplits(t *testing.T) {
+	in := `[{"start":"20260302T090000Z","end":"20260302T110000Z","tags":["deep work"]},
+		{"start":"20260302T120000Z"}]`
+	sessions, err := Read(strings.NewReader(in), FormatTimewarrior, nil, 30*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 4 {
+		t.Fatalf("got %d sessions, want 4 from two hours and none from the open interval", len(sessions))
+	}
+	if tags := sessions[0].Tags; len(tags) != 1 || tags[0] != "deep-work" {
+		t.Errorf("tags = %v", tags)
+	}
+
+	// No work duration keeps entries whole.
+	sessions, err = Read(strings.NewReader(in), FormatTimewarrior, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 {
+		t.Errorf("got %d sessions, want 1", len(sessions))
+	}
+}
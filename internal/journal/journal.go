@@ -0,0 +1,170 @@
+// Package journal renders a day of sessions as a human-readable log in
+// Markdown or Org mode, using text/template so the layout can be replaced.
+//
+// Templates are executed with a Day. Besides the standard functions they
+// can use clock (RFC 3339 time to "15:04"), duration (seconds to "1h25m"),
+// join (strings.Join), interruptions (a session's interruption count) and
+// plural (a count with a word, e.g. "1 interruption").
+package journal
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatOrg      Format = "org"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatMarkdown, FormatOrg:
+		return f, nil
+	case "markdown":
+		return FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown format %q (use md or org)", s)
+}
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+// DefaultTemplate returns the built-in template for format.
+func DefaultTemplate(format Format) (string, error) {
+	data, err := templates.ReadFile("templates/journal." + string(format) + ".tmpl")
+	if err != nil {
+		return "", fmt.Errorf("no template for format %q", format)
+	}
+	return string(data), nil
+}
+
+// Day is the data a journal template is executed with.
+type Day struct {
+	Date          string
+	Weekday       string
+	Sessions      []storage.Session
+	Completed     int
+	Goal          int
+	GoalMet       bool
+	DayOff        bool
+	FocusSeconds  int
+	Interruptions int
+}
+
+// Load collects the sessions completed on date (YYYY-MM-DD), a day with the
+// given goal.
+func Load(store *storage.Storage, date string, goal storage.DayGoal) (Day, error) {
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return Day{}, fmt.Errorf("invalid date %q", date)
+	}
+
+	day := Day{Date: date, Weekday: t.Weekday().String(), Goal: goal.Goal, DayOff: goal.Off}
+	err = store.Sessions(date, date, func(s storage.Session) error {
+		day.Sessions = append(day.Sessions, s)
+		day.FocusSeconds += s.DurationSeconds
+		day.Interruptions += s.InterruptionsInternal + s.InterruptionsExternal
+		return nil
+	})
+	day.Completed = len(day.Sessions)
+	day.GoalMet = goal.Goal > 0 && day.Completed >= goal.Goal
+	return day, err
+}
+
+var funcs = template.FuncMap{
+	"clock": func(rfc3339 string) string {
+		t, err := time.Parse(time.RFC3339, rfc3339)
+		if err != nil {
+			return "--:--"
+		}
+		return t.Local().Format("15:04")
+	},
+	"duration": func(seconds int) string {
+		d := (time.Duration(seconds) * time.Second).Round(time.Minute)
+		if d < time.Hour {
+			return fmt.Sprintf("%dm", int(d.Minutes()))
+		}
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	},
+	"join": strings.Join,
+	"interruptions": func(s storage.Session) int {
+		return s.InterruptionsInternal + s.InterruptionsExternal
+	},
+	// plural writes n with word, adding an s unless n is 1.
+	"plural": func(n int, word string) string {
+		if n != 1 {
+			word += "s"
+		}
+		return fmt.Sprintf("%d %s", n, word)
+	},
+}
+
+// Template parses the template at path, or the built-in one for format
+// when path is empty.
+func Template(format Format, path string) (*template.Template, error) {
+	if path == "" {
+		text, err := DefaultTemplate(format)
+		if err != nil {
+			return nil, err
+		}
+		return template.New("journal." + string(format)).Funcs(funcs).Parse(text)
+	}
+
+	data, err := os.ReadFile(expandHome(path))
+	if err != nil {
+		return nil, err
+	}
+	return template.New(filepath.Base(path)).Funcs(funcs).Parse(string(data))
+}
+
+// Render writes day to w using tmpl.
+func Render(w io.Writer, tmpl *template.Template, day Day) error {
+	return tmpl.Execute(w, day)
+}
+
+// Append renders day and appends it to the file at path, separated from
+// any existing content by a blank line.
+func Append(path string, tmpl *template.Template, day Day) error {
+	var b strings.Builder
+	if err := Render(&b, tmpl, day); err != nil {
+		return err
+	}
+
+	path = expandHome(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		if _, err := io.WriteString(f, "\n"); err != nil {
+			return err
+		}
+	}
+	_, err = io.WriteString(f, b.String())
+	return err
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
@@ -0,0 +1,45 @@
+package journal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func TestRenderDefaults(t *testing.T) {
+	day := Day{
+		Date:    "2026-03-02",
+		Weekday: "Monday",
+		Sessions: []storage.Session{
+			{StartedAt: "2026-03-02T09:00:00Z", CompletedAt: "2026-03-02T09:25:00Z", DurationSeconds: 1500, InterruptionsExternal: 1},
+			// Too short to show as minutes.
+			{StartedAt: "2026-03-02T10:00:00Z", CompletedAt: "2026-03-02T10:00:20Z", DurationSeconds: 20},
+		},
+		Completed:     2,
+		Goal:          8,
+		FocusSeconds:  1520,
+		Interruptions: 1,
+	}
+
+	for _, format := range []Format{FormatMarkdown, FormatOrg} {
+		tmpl, err := Template(format, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b strings.Builder
+		if err := Render(&b, tmpl, day); err != nil {
+			t.Fatal(err)
+		}
+		out := b.String()
+		if !strings.Contains(out, "1 interruption\n") {
+			t.Errorf("%s: want the total as \"1 interruption\":\n%s", format, out)
+		}
+		if strings.Contains(out, "0m") {
+			t.Errorf("%s: a 20s session shows as 0m:\n%s", format, out)
+		}
+		if !strings.Contains(out, "25m") {
+			t.Errorf("%s: want the 25m session's duration:\n%s", format, out)
+		}
+	}
+}
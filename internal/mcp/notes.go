@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/philleif/pomme/internal/client"
+)
+
+func addNoteTools(s *server.MCPServer, c *client.Client) {
+	noteTool := mcp.NewTool("pomme_note",
+		mcp.WithDescription("Record what was done in a pomodoro. Applies to the running work interval, or the last completed one. #hashtags in the note become tags"),
+		mcp.WithString("note", mcp.Description("Short note, e.g. \"fixed login bug #oncall\"")),
+		mcp.WithString("tags", mcp.Description("Comma-separated extra tags, e.g. \"review,frontend\"")),
+	)
+	s.AddTool(noteTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var tags []string
+		for _, tag := range strings.Split(req.GetString("tags", ""), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		result, err := c.Note(req.GetString("note", ""), tags)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to add note: %v", err)), nil
+		}
+		where := "the last completed work interval"
+		if result.Target == "current" {
+			where = "the current work interval"
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Note saved for %s. Tags: %s", where, strings.Join(result.Tags, ", "))), nil
+	})
+
+	statsTool := mcp.NewTool("pomme_stats",
+		mcp.WithDescription("Get today's count, the last 7 days, tag usage this week, today's notes, goal streaks and weekly goal hit rates, optionally filtered by tag"),
+		mcp.WithString("tag", mcp.Description("Only count intervals with this tag")),
+	)
+	s.AddTool(statsTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		stats, err := c.Stats(req.GetString("tag", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get stats: %v", err)), nil
+		}
+		data, _ := json.MarshalIndent(stats, "", "  ")
+		return mcp.NewToolResultText(string(data)), nil
+	})
+}
+
+func addInterruptTool(s *server.MCPServer, c *client.Client) {
+	interruptTool := mcp.NewTool("pomme_interrupt",
+		mcp.WithDescription("Log an interruption of the running work interval. Internal: your own urge to switch tasks; external: someone or something else"),
+		mcp.WithString("kind", mcp.Required(), mcp.Enum("internal", "external"), mcp.Description("internal or external")),
+		mcp.WithString("note", mcp.Description("Optional detail, e.g. \"Slack ping from ops\"")),
+	)
+	s.AddTool(interruptTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		kind, err := req.RequireString("kind")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		interruption, err := c.Interrupt(kind, req.GetString("note", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to log interruption: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Logged %s interruption", interruption.Kind)), nil
+	})
+}
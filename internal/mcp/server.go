@@ -90,5 +90,11 @@ func Run() error {
 		return mcp.NewToolResultText(fmt.Sprintf("Messages blocking: %s", state)), nil
 	})
 
+	addTaskTools(s, c)
+	addNoteTools(s, c)
+	addInterruptTool(s, c)
+	addStatsQueryTool(s, c)
+	addHeatmapTool(s, c)
+
 	return server.ServeStdio(s)
 }
@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/philleif/pomme/internal/client"
+	"github.com/philleif/pomme/internal/storage"
+)
+
+func addStatsQueryTool(s *server.MCPServer, c *client.Client) {
+	queryTool := mcp.NewTool("pomme_stats_query",
+		mcp.WithDescription("Count pomodoros and focused minutes over any date range, grouped by day, ISO week, month, weekday, hour of day, weekday and hour (key 1-09 for Monday 09:00), task or tag"),
+		mcp.WithString("by", mcp.Description("Grouping"),
+			mcp.Enum("day", "week", "month", "weekday", "hour", "weekday-hour", "task", "tag")),
+		mcp.WithString("from", mcp.Description("First date, YYYY-MM-DD (inclusive); omit for no limit")),
+		mcp.WithString("to", mcp.Description("Last date, YYYY-MM-DD (inclusive); omit for no limit")),
+		mcp.WithString("tag", mcp.Description("Only count intervals with this tag")),
+		mcp.WithNumber("task", mcp.Description("Only count intervals linked to this task id")),
+		mcp.WithBoolean("fill", mcp.Description("Include empty groups, e.g. days without pomodoros (needs from and to for dates)")),
+	)
+	s.AddTool(queryTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		groupBy, err := storage.ParseGroupBy(req.GetString("by", "day"))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		buckets, err := c.StatsQuery(storage.StatsQuery{
+			From:    req.GetString("from", ""),
+			To:      req.GetString("to", ""),
+			GroupBy: groupBy,
+			Tag:     req.GetString("tag", ""),
+			TaskID:  int64(req.GetInt("task", 0)),
+			Fill:    req.GetBool("fill", false),
+		})
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to query stats: %v", err)), nil
+		}
+		data, _ := json.MarshalIndent(buckets, "", "  ")
+		return mcp.NewToolResultText(string(data)), nil
+	})
+}
+
+func addHeatmapTool(s *server.MCPServer, c *client.Client) {
+	heatmapTool := mcp.NewTool("pomme_heatmap",
+		mcp.WithDescription("Pomodoros by weekday and hour of day they started, to see when focus happens. counts and focus_minutes are 7x24 matrices: rows Monday to Sunday, columns local hours 0 to 23"),
+		mcp.WithString("from", mcp.Description("First date, YYYY-MM-DD (inclusive); omit for no limit")),
+		mcp.WithString("to", mcp.Description("Last date, YYYY-MM-DD (inclusive); omit for no limit")),
+	)
+	s.AddTool(heatmapTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		heatmap, err := c.Heatmap(req.GetString("from", ""), req.GetString("to", ""))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to get heatmap: %v", err)), nil
+		}
+		data, _ := json.MarshalIndent(heatmap, "", "  ")
+		return mcp.NewToolResultText(string(data)), nil
+	})
+}
@@ -0,0 +1,92 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/philleif/pomme/internal/client"
+)
+
+func addTaskTools(s *server.MCPServer, c *client.Client) {
+	listTool := mcp.NewTool("pomme_task_list",
+		mcp.WithDescription("List tasks with their pomodoro estimates and completed counts"),
+		mcp.WithBoolean("all", mcp.Description("Include finished tasks")),
+	)
+	s.AddTool(listTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tasks, err := c.Tasks(req.GetBool("all", false))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to list tasks: %v", err)), nil
+		}
+		data, _ := json.MarshalIndent(tasks, "", "  ")
+		return mcp.NewToolResultText(string(data)), nil
+	})
+
+	addTool := mcp.NewTool("pomme_task_add",
+		mcp.WithDescription("Add a task to work on in pomodoros"),
+		mcp.WithString("title", mcp.Required(), mcp.Description("What the task is")),
+		mcp.WithString("project", mcp.Description("Optional project name")),
+		mcp.WithNumber("estimate", mcp.Description("Estimated number of pomodoros")),
+		mcp.WithBoolean("select", mcp.Description("Make it the current task")),
+	)
+	s.AddTool(addTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		title, err := req.RequireString("title")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		task, err := c.AddTask(title, req.GetString("project", ""), req.GetInt("estimate", 0), req.GetBool("select", false))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to add task: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Added task %d: %s", task.ID, task.Title)), nil
+	})
+
+	selectTool := mcp.NewTool("pomme_task_select",
+		mcp.WithDescription("Set the current task; completed work intervals are linked to it. Use id 0 to clear"),
+		mcp.WithNumber("id", mcp.Required(), mcp.Description("Task id from pomme_task_list")),
+	)
+	s.AddTool(selectTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		id, err := req.RequireInt("id")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		status, err := c.SelectTask(int64(id))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to select task: %v", err)), nil
+		}
+		if status.CurrentTask == nil {
+			return mcp.NewToolResultText("No current task"), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Current task: %s", status.CurrentTask.Title)), nil
+	})
+
+	doneTool := mcp.NewTool("pomme_task_done",
+		mcp.WithDescription("Mark a task as done (defaults to the current task)"),
+		mcp.WithNumber("id", mcp.Description("Task id; omit for the current task")),
+	)
+	s.AddTool(doneTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		task, err := c.CompleteTask(int64(req.GetInt("id", 0)))
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to complete task: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Done: %s (%d pomodoros)", task.Title, task.Completed)), nil
+	})
+
+	pickTool := mcp.NewTool("pomme_task_pick",
+		mcp.WithDescription("Set the current task from Taskwarrior, by Taskwarrior ID or UUID"),
+		mcp.WithString("ref", mcp.Required(), mcp.Description("Taskwarrior task ID or UUID")),
+	)
+	s.AddTool(pickTool, func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ref, err := req.RequireString("ref")
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		status, err := c.PickTask(ref)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("Failed to pick task: %v", err)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Current task: %s", status.CurrentTask.Title)), nil
+	})
+}
@@ -1,10 +1,14 @@
 package menubar
 
 import (
+	"fmt"
+	"os"
 	"os/exec"
+	"strconv"
 
 	"fyne.io/systray"
 	"github.com/philleif/pomme/internal/daemon"
+	"github.com/philleif/pomme/internal/paths"
 )
 
 type MenuBar struct {
@@ -29,7 +33,7 @@ func (m *MenuBar) Run() {
 }
 
 func (m *MenuBar) onReady() {
-	systray.SetTitle("🍅 25:00")
+	systray.SetTitle(m.daemon.GetStatus().StatusLine)
 	systray.SetTooltip("Pomme - Pomodoro Timer")
 
 	m.mStart = systray.AddMenuItem("Start", "Start timer")
@@ -59,19 +63,16 @@ func (m *MenuBar) handleClicks() {
 	for {
 		select {
 		case <-m.mStart.ClickedCh:
-			m.daemon.Timer().Start()
-			m.daemon.Blocker().SetInInterval(true)
+			m.daemon.Handle(daemon.Command{Action: "start"})
 
 		case <-m.mPause.ClickedCh:
-			m.daemon.Timer().Pause()
-			m.daemon.Blocker().SetInInterval(false)
+			m.daemon.Handle(daemon.Command{Action: "pause"})
 
 		case <-m.mSkip.ClickedCh:
-			m.daemon.Timer().Skip()
+			m.daemon.Handle(daemon.Command{Action: "skip"})
 
 		case <-m.mReset.ClickedCh:
-			m.daemon.Timer().Reset()
-			m.daemon.Blocker().SetInInterval(false)
+			m.daemon.Handle(daemon.Command{Action: "reset"})
 
 		case <-m.mBlock.ClickedCh:
 			enabled := m.daemon.Blocker().ToggleEnabled()
@@ -99,10 +100,14 @@ func (m *MenuBar) handleClicks() {
 }
 
 func (m *MenuBar) openTUI() {
-	script := `tell application "Terminal"
+	command := "pomme"
+	if home := os.Getenv(paths.HomeEnv); home != "" {
+		command = fmt.Sprintf("pomme --home %s", strconv.Quote(home))
+	}
+	script := fmt.Sprintf(`tell application "Terminal"
 		activate
-		do script "pomme"
-	end tell`
+		do script %s
+	end tell`, strconv.Quote(command))
 	exec.Command("osascript", "-e", script).Run()
 }
 
@@ -0,0 +1,176 @@
+// Package paths resolves where pomme keeps its config, data and socket.
+//
+// POMME_HOME puts everything in one directory. Otherwise macOS uses ~/.pomme
+// and other systems follow the XDG base directory spec, migrating an existing
+// ~/.pomme on first use.
+package paths
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+const HomeEnv = "POMME_HOME"
+
+var migrateOnce sync.Once
+
+func homeOverride() string {
+	return os.Getenv(HomeEnv)
+}
+
+func useXDG() bool {
+	return homeOverride() == "" && runtime.GOOS != "darwin"
+}
+
+func legacyDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".pomme"), nil
+}
+
+func xdgDir(env, fallback string) (string, error) {
+	if base := os.Getenv(env); filepath.IsAbs(base) {
+		return filepath.Join(base, "pomme"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fallback, "pomme"), nil
+}
+
+func configDir() (string, error) {
+	if dir := homeOverride(); dir != "" {
+		return dir, nil
+	}
+	if useXDG() {
+		return xdgDir("XDG_CONFIG_HOME", ".config")
+	}
+	return legacyDir()
+}
+
+func dataDir() (string, error) {
+	if dir := homeOverride(); dir != "" {
+		return dir, nil
+	}
+	if useXDG() {
+		return xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+	}
+	return legacyDir()
+}
+
+func ensure(dir string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	migrateOnce.Do(migrateLegacy)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// ConfigDir returns the directory holding config.json, creating it if needed.
+func ConfigDir() (string, error) {
+	return ensure(configDir())
+}
+
+// DataDir returns the directory holding the database, creating it if needed.
+func DataDir() (string, error) {
+	return ensure(dataDir())
+}
+
+// RuntimeDir returns the directory for the daemon socket. On XDG systems this
+// is $XDG_RUNTIME_DIR/pomme when set, otherwise the data directory.
+func RuntimeDir() (string, error) {
+	if useXDG() {
+		if base := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(base) {
+			dir := filepath.Join(base, "pomme")
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return "", err
+			}
+			return dir, nil
+		}
+	}
+	return DataDir()
+}
+
+func SocketPath() (string, error) {
+	dir, err := RuntimeDir()
+	if err != nil {
+		return "", fmt.Errorf("no socket directory: %w", err)
+	}
+	return filepath.Join(dir, "pomme.sock"), nil
+}
+
+// LogPath returns the file the daemon's errors are written to when it runs
+// in the background.
+func LogPath() (string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon.log"), nil
+}
+
+// migrateLegacy moves config and database files from ~/.pomme into the XDG
+// directories. Files already present at the destination are left alone.
+func migrateLegacy() {
+	if !useXDG() {
+		return
+	}
+
+	legacy, err := legacyDir()
+	if err != nil {
+		return
+	}
+	if info, err := os.Stat(legacy); err != nil || !info.IsDir() {
+		return
+	}
+
+	cfgDir, err := configDir()
+	if err != nil {
+		return
+	}
+	dDir, err := dataDir()
+	if err != nil {
+		return
+	}
+
+	moves := map[string]string{
+		"config.json":  cfgDir,
+		"pomme.db":     dDir,
+		"pomme.db-wal": dDir,
+		"pomme.db-shm": dDir,
+	}
+	for name, dest := range moves {
+		if err := moveFile(filepath.Join(legacy, name), filepath.Join(dest, name)); err != nil {
+			fmt.Fprintf(os.Stderr, "pomme: could not migrate %s: %v\n", name, err)
+		}
+	}
+
+	os.Remove(filepath.Join(legacy, "pomme.sock"))
+	// Only succeeds once the directory is empty.
+	os.Remove(legacy)
+}
+
+func moveFile(src, dst string) error {
+	if _, err := os.Stat(src); err != nil {
+		return nil
+	}
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.Rename(src, dst)
+}
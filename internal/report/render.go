@@ -0,0 +1,200 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Write renders r to w in format.
+func Write(w io.Writer, r Report, format Format) error {
+	switch format {
+	case FormatText:
+		return writeText(w, r)
+	case FormatMarkdown:
+		return writeMarkdown(w, r)
+	case FormatJSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// Title names the period, e.g. "Week 2026-W42 (Oct 12 – Oct 18)".
+func (p Period) Title() string {
+	start, end := p.bounds()
+	switch p.Kind {
+	case "week":
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("Week %d-W%02d (%s – %s)", year, week, start.Format("Jan 2"), end.Format("Jan 2"))
+	case "month":
+		return start.Format("January 2006")
+	}
+	return p.From + " – " + p.To
+}
+
+func (p Period) previousName() string {
+	if p.Kind == "range" {
+		return "previous period"
+	}
+	return "last " + p.Kind
+}
+
+// line is one "label value" row of a report's summary.
+type line struct{ label, value string }
+
+func summaryLines(r Report) []line {
+	vs := "vs " + r.Period.previousName()
+	lines := []line{
+		{"Pomodoros", fmt.Sprintf("%d%s", r.Pomodoros, change(r.Pomodoros, r.Previous.Pomodoros, vs))},
+		{"Focus", formatMinutes(r.FocusMinutes) + change(r.FocusMinutes, r.Previous.FocusMinutes, vs)},
+	}
+
+	goal := fmt.Sprintf("%d/%d days", r.GoalDays, r.WorkDays)
+	if r.WorkDays > 0 {
+		goal += fmt.Sprintf(" (%.0f%%)", r.GoalRate*100)
+	}
+	if r.Previous.WorkDays > 0 {
+		goal += fmt.Sprintf(", %.0f%% %s", r.Previous.GoalRate*100, r.Period.previousName())
+	}
+	lines = append(lines, line{"Goal met", goal})
+
+	if r.BestDay != nil {
+		lines = append(lines, line{"Best day", dayLabel(*r.BestDay)})
+	}
+	if r.WorstDay != nil {
+		lines = append(lines, line{"Worst day", dayLabel(*r.WorstDay)})
+	}
+	lines = append(lines, line{"Interruptions", fmt.Sprintf("%d internal, %d external (%.2f per pomodoro)",
+		r.Interruptions.Internal, r.Interruptions.External, r.InterruptionsPerPom)})
+	return lines
+}
+
+// change renders the difference from the previous period, e.g.
+// " (+20% vs last week)"; empty when there is nothing to compare.
+func change(current, previous int, vs string) string {
+	if previous == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.0f%% %s)", Change(current, previous)*100, vs)
+}
+
+func dayLabel(d Day) string {
+	t, _ := time.ParseInLocation("2006-01-02", d.Date, time.Local)
+	return fmt.Sprintf("%s %s, %d pomodoros, %s", t.Format("Mon"), d.Date, d.Pomodoros, formatMinutes(d.FocusMinutes))
+}
+
+func bar(n, most, width int) string {
+	if most == 0 || n == 0 {
+		return ""
+	}
+	return strings.Repeat("█", (n*width+most-1)/most)
+}
+
+func writeText(w io.Writer, r Report) error {
+	var b strings.Builder
+	b.WriteString(r.Period.Title() + "\n\n")
+	for _, l := range summaryLines(r) {
+		fmt.Fprintf(&b, "%-14s %s\n", l.label, l.value)
+	}
+
+	most := 0
+	for _, d := range r.Days {
+		most = max(most, d.Pomodoros)
+	}
+	b.WriteString("\nDays\n")
+	for _, d := range r.Days {
+		t, _ := time.ParseInLocation("2006-01-02", d.Date, time.Local)
+		mark := " "
+		switch {
+		case d.GoalMet:
+			mark = "✓"
+		case d.Off:
+			mark = "-"
+		}
+		row := fmt.Sprintf("  %s %s %3d %s %s", t.Format("Mon"), d.Date, d.Pomodoros, mark, bar(d.Pomodoros, most, 20))
+		b.WriteString(strings.TrimRight(row, " ") + "\n")
+	}
+
+	most = 0
+	for _, p := range r.PartOfDay {
+		most = max(most, p.Pomodoros)
+	}
+	b.WriteString("\nTime of day\n")
+	for _, p := range r.PartOfDay {
+		row := fmt.Sprintf("  %-10s %s %3d %s", p.Name, p.Hours, p.Pomodoros, bar(p.Pomodoros, most, 20))
+		b.WriteString(strings.TrimRight(row, " ") + "\n")
+	}
+
+	if len(r.TopTasks) > 0 {
+		b.WriteString("\nTop tasks\n")
+		for _, t := range r.TopTasks {
+			fmt.Fprintf(&b, "  %3d  %7s  %s\n", t.Count, formatMinutes(t.FocusMinutes), t.Label)
+		}
+	}
+	if len(r.TopTags) > 0 {
+		b.WriteString("\nTop tags\n")
+		for _, t := range r.TopTags {
+			fmt.Fprintf(&b, "  %3d  %7s  #%s\n", t.Count, formatMinutes(t.FocusMinutes), t.Label)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdown(w io.Writer, r Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", r.Period.Title())
+	for _, l := range summaryLines(r) {
+		fmt.Fprintf(&b, "- **%s:** %s\n", l.label, l.value)
+	}
+
+	b.WriteString("\n| Day | Pomodoros | Focus | Goal |\n|-----|----------:|------:|------|\n")
+	for _, d := range r.Days {
+		t, _ := time.ParseInLocation("2006-01-02", d.Date, time.Local)
+		goal := fmt.Sprint(d.Goal)
+		switch {
+		case d.GoalMet:
+			goal += " ✓"
+		case d.Off && d.Goal == 0:
+			goal = "off"
+		}
+		fmt.Fprintf(&b, "| %s %s | %d | %s | %s |\n", t.Format("Mon"), d.Date, d.Pomodoros, formatMinutes(d.FocusMinutes), goal)
+	}
+
+	b.WriteString("\n### Time of day\n\n| Part | Hours | Pomodoros |\n|------|-------|----------:|\n")
+	for _, p := range r.PartOfDay {
+		fmt.Fprintf(&b, "| %s | %s | %d |\n", p.Name, p.Hours, p.Pomodoros)
+	}
+
+	if len(r.TopTasks) > 0 {
+		b.WriteString("\n### Top tasks\n\n")
+		for _, t := range r.TopTasks {
+			fmt.Fprintf(&b, "- %s: %d pomodoros, %s\n", t.Label, t.Count, formatMinutes(t.FocusMinutes))
+		}
+	}
+	if len(r.TopTags) > 0 {
+		b.WriteString("\n### Top tags\n\n")
+		for _, t := range r.TopTags {
+			fmt.Fprintf(&b, "- #%s: %d pomodoros, %s\n", t.Label, t.Count, formatMinutes(t.FocusMinutes))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatMinutes renders minutes as "45m" or "3h05m".
+func formatMinutes(m int) string {
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", m/60, m%60)
+}
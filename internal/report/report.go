@@ -0,0 +1,282 @@
+// Package report summarizes a period of pomodoros (a week, a month or any
+// range of days) and compares it with the period before, for reading in a
+// terminal or pasting into a status update.
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/philleif/pomme/internal/storage"
+)
+
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatMarkdown Format = "md"
+	FormatJSON     Format = "json"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case FormatText, FormatMarkdown, FormatJSON:
+		return f, nil
+	case "txt":
+		return FormatText, nil
+	case "markdown":
+		return FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown format %q (use text, md or json)", s)
+}
+
+// Period is a range of whole days, From and To inclusive (YYYY-MM-DD).
+// Kind is "week", "month" or "range" and decides what the previous period is.
+type Period struct {
+	Kind string `json:"kind"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Week is the ISO week (Monday to Sunday) containing t.
+func Week(t time.Time) Period {
+	monday := t.AddDate(0, 0, -((int(t.Weekday()) + 6) % 7))
+	return Period{Kind: "week", From: monday.Format("2006-01-02"), To: monday.AddDate(0, 0, 6).Format("2006-01-02")}
+}
+
+// Month is the calendar month containing t.
+func Month(t time.Time) Period {
+	first := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+	return Period{Kind: "month", From: first.Format("2006-01-02"), To: first.AddDate(0, 1, -1).Format("2006-01-02")}
+}
+
+// Range is the days from from to to, inclusive.
+func Range(from, to string) (Period, error) {
+	start, err1 := time.ParseInLocation("2006-01-02", from, time.Local)
+	end, err2 := time.ParseInLocation("2006-01-02", to, time.Local)
+	if err1 != nil || err2 != nil {
+		return Period{}, fmt.Errorf("invalid range %s..%s (use YYYY-MM-DD)", from, to)
+	}
+	if end.Before(start) {
+		return Period{}, fmt.Errorf("range ends before it starts: %s..%s", from, to)
+	}
+	return Period{Kind: "range", From: from, To: to}, nil
+}
+
+// Previous is the period just before p: the week or month before, or a
+// range of the same length ending the day before p starts.
+func (p Period) Previous() Period {
+	start, end := p.bounds()
+	switch p.Kind {
+	case "week":
+		return Week(start.AddDate(0, 0, -7))
+	case "month":
+		return Month(start.AddDate(0, 0, -1))
+	}
+	days := p.days(start, end)
+	last := start.AddDate(0, 0, -1)
+	return Period{Kind: p.Kind, From: last.AddDate(0, 0, 1-days).Format("2006-01-02"), To: last.Format("2006-01-02")}
+}
+
+func (p Period) bounds() (time.Time, time.Time) {
+	start, _ := time.ParseInLocation("2006-01-02", p.From, time.Local)
+	end, _ := time.ParseInLocation("2006-01-02", p.To, time.Local)
+	return start, end
+}
+
+func (p Period) days(start, end time.Time) int {
+	n := 0
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		n++
+	}
+	return n
+}
+
+// Day is one day of a report.
+type Day struct {
+	Date         string `json:"date"`
+	Pomodoros    int    `json:"pomodoros"`
+	FocusMinutes int    `json:"focus_minutes"`
+	Goal         int    `json:"goal"`
+	Off          bool   `json:"off,omitempty"`
+	GoalMet      bool   `json:"goal_met"`
+}
+
+// Summary holds the totals of a period.
+type Summary struct {
+	Pomodoros    int `json:"pomodoros"`
+	FocusMinutes int `json:"focus_minutes"`
+
+	// GoalDays of WorkDays met their goal. Days off and days after today
+	// are not work days.
+	GoalDays int     `json:"goal_days"`
+	WorkDays int     `json:"work_days"`
+	GoalRate float64 `json:"goal_rate"`
+}
+
+// PartOfDay groups the hours work intervals started in.
+type PartOfDay struct {
+	Name      string `json:"name"`
+	Hours     string `json:"hours"`
+	Pomodoros int    `json:"pomodoros"`
+}
+
+var partsOfDay = []struct {
+	name        string
+	first, last int
+}{
+	{"Night", 0, 5},
+	{"Morning", 6, 11},
+	{"Afternoon", 12, 17},
+	{"Evening", 18, 23},
+}
+
+// Report is everything pomme knows about a period.
+type Report struct {
+	Period Period `json:"period"`
+	Summary
+
+	Days     []Day `json:"days"`
+	BestDay  *Day  `json:"best_day,omitempty"`
+	WorstDay *Day  `json:"worst_day,omitempty"`
+
+	Hours     []storage.Bucket `json:"hours"`
+	PartOfDay []PartOfDay      `json:"part_of_day"`
+	TopTasks  []storage.Bucket `json:"top_tasks"`
+	TopTags   []storage.Bucket `json:"top_tags"`
+
+	Interruptions       storage.InterruptionStats `json:"interruptions"`
+	InterruptionsPerPom float64                   `json:"interruptions_per_pomodoro"`
+
+	PreviousPeriod Period  `json:"previous_period"`
+	Previous       Summary `json:"previous"`
+}
+
+// topN is how many tasks and tags a report lists.
+const topN = 5
+
+// Build collects the report for period. plan decides each day's goal and
+// today ends the days that count towards the goal hit rate.
+func Build(store *storage.Storage, plan storage.GoalPlan, period Period, today time.Time) (Report, error) {
+	r := Report{Period: period, PreviousPeriod: period.Previous()}
+
+	var err error
+	r.Days, r.Summary, err = days(store, plan, period, today)
+	if err != nil {
+		return r, err
+	}
+	_, r.Previous, err = days(store, plan, r.PreviousPeriod, today)
+	if err != nil {
+		return r, err
+	}
+
+	end := today.Format("2006-01-02")
+	for i := range r.Days {
+		d := &r.Days[i]
+		if d.Date > end {
+			break
+		}
+		if r.BestDay == nil || d.Pomodoros > r.BestDay.Pomodoros {
+			r.BestDay = d
+		}
+		// Today isn't over, so it can't be the worst day yet.
+		if !d.Off && d.Date < end && (r.WorstDay == nil || d.Pomodoros < r.WorstDay.Pomodoros) {
+			r.WorstDay = d
+		}
+	}
+	if r.BestDay != nil && r.BestDay.Pomodoros == 0 {
+		r.BestDay = nil
+	}
+
+	r.Hours, err = store.StatsQuery(storage.StatsQuery{From: period.From, To: period.To, GroupBy: storage.GroupHour, Fill: true})
+	if err != nil {
+		return r, err
+	}
+	for _, part := range partsOfDay {
+		p := PartOfDay{Name: part.name, Hours: fmt.Sprintf("%02d-%02d", part.first, part.last+1)}
+		for _, h := range r.Hours[part.first : part.last+1] {
+			p.Pomodoros += h.Count
+		}
+		r.PartOfDay = append(r.PartOfDay, p)
+	}
+
+	r.TopTasks, err = top(store, period, storage.GroupTask)
+	if err != nil {
+		return r, err
+	}
+	r.TopTags, err = top(store, period, storage.GroupTag)
+	if err != nil {
+		return r, err
+	}
+
+	r.Interruptions, err = store.InterruptionCounts(period.From, period.To)
+	if err != nil {
+		return r, err
+	}
+	r.InterruptionsPerPom = r.Interruptions.PerInterval()
+
+	return r, nil
+}
+
+// days loads every day of period and totals them.
+func days(store *storage.Storage, plan storage.GoalPlan, period Period, today time.Time) ([]Day, Summary, error) {
+	var sum Summary
+	buckets, err := store.StatsQuery(storage.StatsQuery{From: period.From, To: period.To, GroupBy: storage.GroupDay, Fill: true})
+	if err != nil {
+		return nil, sum, err
+	}
+
+	end := today.Format("2006-01-02")
+	list := make([]Day, len(buckets))
+	for i, b := range buckets {
+		goal := plan.On(b.Key)
+		d := Day{Date: b.Key, Pomodoros: b.Count, FocusMinutes: b.FocusMinutes, Goal: goal.Goal, Off: goal.Off}
+		d.GoalMet = d.Goal > 0 && d.Pomodoros >= d.Goal
+		list[i] = d
+
+		sum.Pomodoros += d.Pomodoros
+		sum.FocusMinutes += d.FocusMinutes
+		if d.Date > end || d.Off {
+			continue
+		}
+		sum.WorkDays++
+		if d.GoalMet {
+			sum.GoalDays++
+		}
+	}
+	if sum.WorkDays > 0 {
+		sum.GoalRate = float64(sum.GoalDays) / float64(sum.WorkDays)
+	}
+	return list, sum, nil
+}
+
+// top returns the most-worked tasks or tags of period, skipping work with
+// no task or tag.
+func top(store *storage.Storage, period Period, groupBy storage.GroupBy) ([]storage.Bucket, error) {
+	buckets, err := store.StatsQuery(storage.StatsQuery{From: period.From, To: period.To, GroupBy: groupBy})
+	if err != nil {
+		return nil, err
+	}
+	list := make([]storage.Bucket, 0, len(buckets))
+	for _, b := range buckets {
+		if b.Key != "" {
+			list = append(list, b)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Count > list[j].Count })
+	if len(list) > topN {
+		list = list[:topN]
+	}
+	return list, nil
+}
+
+// Change is the relative change from previous to current, e.g. 0.25 for 25%
+// more. It is zero when there is nothing to compare against.
+func Change(current, previous int) float64 {
+	if previous == 0 {
+		return 0
+	}
+	return float64(current-previous) / float64(previous)
+}
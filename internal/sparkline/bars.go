@@ -0,0 +1,195 @@
+package sparkline
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+)
+
+// Scale is what the top of a BarChart stands for.
+type Scale string
+
+const (
+	// ScaleGoal puts the largest goal at the top; bigger values are cut off
+	// there and marked as overflowing.
+	ScaleGoal Scale = "goal"
+	// ScaleAuto fits the largest value or goal, whichever is bigger.
+	ScaleAuto Scale = "auto"
+)
+
+func ParseScale(s string) (Scale, error) {
+	switch sc := Scale(strings.ToLower(s)); sc {
+	case ScaleGoal, ScaleAuto:
+		return sc, nil
+	}
+	return "", fmt.Errorf("unknown scale %q (use goal or auto)", s)
+}
+
+// BarColors are the colors of a BarChart.
+type BarColors struct {
+	Bar        color.NRGBA
+	BarGoalMet color.NRGBA
+	Overflow   color.NRGBA // cap on bars cut off by ScaleGoal
+	Goal       color.NRGBA
+	Axis       color.NRGBA
+	Today      color.NRGBA // band behind today's bar
+}
+
+func DefaultBarColors() BarColors {
+	return BarColors{
+		Bar:        color.NRGBA{244, 169, 155, 255},
+		BarGoalMet: color.NRGBA{255, 99, 71, 255},
+		Overflow:   color.NRGBA{255, 215, 0, 255},
+		Goal:       color.NRGBA{150, 150, 150, 200},
+		Axis:       color.NRGBA{100, 100, 100, 255},
+		Today:      color.NRGBA{128, 128, 128, 48},
+	}
+}
+
+// BarChart is a pixel bar chart of daily counts, drawn on a transparent
+// background for GenerateGraph.
+type BarChart struct {
+	Values []int
+	// Goals holds each bar's goal; a goal line is drawn at its height and
+	// zero (a day off) draws none. Missing entries use the last goal.
+	Goals []int
+	Scale Scale
+	Today int // index of the bar to highlight, -1 for none
+	// Width and Height are in logical pixels; the image is PixelRatio times
+	// bigger for HiDPI screens.
+	Width, Height int
+	PixelRatio    int
+	Colors        BarColors
+}
+
+// NewBarChart is a chart of values against one goal with today last and the
+// default colors.
+func NewBarChart(values []int, goal, width, height int) BarChart {
+	return BarChart{
+		Values:     values,
+		Goals:      []int{goal},
+		Scale:      ScaleGoal,
+		Today:      len(values) - 1,
+		Width:      width,
+		Height:     height,
+		PixelRatio: 1,
+		Colors:     DefaultBarColors(),
+	}
+}
+
+func (c BarChart) ratio() int {
+	return max(c.PixelRatio, 1)
+}
+
+// Size is the size of the image in device pixels.
+func (c BarChart) Size() (width, height int) {
+	return c.Width * c.ratio(), c.Height * c.ratio()
+}
+
+func (c BarChart) goal(i int) int {
+	if len(c.Goals) == 0 {
+		return 0
+	}
+	return c.Goals[min(i, len(c.Goals)-1)]
+}
+
+// top is the value at the top of the plot.
+func (c BarChart) top() int {
+	goals, values := 0, 0
+	for i, v := range c.Values {
+		goals = max(goals, c.goal(i))
+		values = max(values, v)
+	}
+	if c.Scale == ScaleGoal && goals > 0 {
+		return goals
+	}
+	return max(goals, values, 1)
+}
+
+// BarLevel is where a bar goes in a plot, in pixels above its baseline.
+type BarLevel struct {
+	Height   int // a value above zero is at least the minimum height
+	Goal     int // height of the goal line, if HasGoal
+	HasGoal  bool
+	GoalMet  bool
+	Overflow bool // the value is above the top of the plot and cut off
+}
+
+// LayoutBars scales values and their goals, as in BarChart, into a plot
+// plotH pixels high. The terminal graphs and the image charts share it, so
+// the same data looks the same in both.
+func LayoutBars(values, goals []int, scale Scale, plotH, minHeight int) []BarLevel {
+	c := BarChart{Values: values, Goals: goals, Scale: scale}
+	top := c.top()
+	levels := make([]BarLevel, len(values))
+	for i, v := range values {
+		goal := c.goal(i)
+		l := BarLevel{
+			Height:   min(v, top) * plotH / top,
+			HasGoal:  goal > 0,
+			GoalMet:  goal > 0 && v >= goal,
+			Overflow: v > top,
+		}
+		if v > 0 {
+			l.Height = max(l.Height, minHeight) // one is still more than none
+		}
+		if l.HasGoal {
+			l.Goal = min(goal, top) * plotH / top
+		}
+		levels[i] = l
+	}
+	return levels
+}
+
+// Raster draws the chart. From the bottom: an axis line, the bars, then a
+// strip of headroom where cut-off bars show their overflow cap. Goal lines
+// are drawn over the bars so a bar that meets its goal still shows it.
+func (c BarChart) Raster() *image.NRGBA {
+	w, h := c.Size()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	if len(c.Values) == 0 || w == 0 || h == 0 {
+		return img
+	}
+	s := c.ratio()
+	col := c.Colors
+
+	axis := s
+	headroom := 3 * s
+	baseline := h - axis
+	plotH := max(baseline-headroom, 1)
+
+	slot := w / len(c.Values)
+	gap := max(slot/6, s)
+	barW := max(slot-gap, 1)
+
+	fill(img, 0, baseline, w, axis, col.Axis)
+	for i, l := range LayoutBars(c.Values, c.Goals, c.Scale, plotH, s) {
+		x := i * slot
+		if i == c.Today {
+			fill(img, x, 0, slot, baseline, col.Today)
+		}
+
+		barColor := col.Bar
+		if l.GoalMet {
+			barColor = col.BarGoalMet
+		}
+		fill(img, x+gap/2, baseline-l.Height, barW, l.Height, barColor)
+		if l.Overflow {
+			fill(img, x+gap/2, 0, barW, headroom-s, col.Overflow)
+		}
+
+		if l.HasGoal {
+			fill(img, x, baseline-l.Goal, slot, s, col.Goal)
+		}
+	}
+	return img
+}
+
+func fill(img *image.NRGBA, x, y, w, h int, c color.NRGBA) {
+	if w <= 0 || h <= 0 || c.A == 0 {
+		return
+	}
+	draw.Draw(img, image.Rect(x, y, x+w, y+h), &image.Uniform{c}, image.Point{}, draw.Over)
+}
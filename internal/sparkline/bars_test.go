@@ -0,0 +1,247 @@
+package sparkline
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// Opaque, distinct colors so every pixel says what was drawn there.
+var testColors = BarColors{
+	Bar:        color.NRGBA{1, 0, 0, 255},
+	BarGoalMet: color.NRGBA{2, 0, 0, 255},
+	Overflow:   color.NRGBA{3, 0, 0, 255},
+	Goal:       color.NRGBA{4, 0, 0, 255},
+	Axis:       color.NRGBA{5, 0, 0, 255},
+	Today:      color.NRGBA{6, 0, 0, 255},
+}
+
+// testChart has 10-pixel slots and a plot 40 pixels high: a 1-pixel axis
+// at y 43 and a 3-pixel headroom strip at the top.
+func testChart(values, goals []int, scale Scale) BarChart {
+	return BarChart{
+		Values:     values,
+		Goals:      goals,
+		Scale:      scale,
+		Today:      -1,
+		Width:      10 * len(values),
+		Height:     44,
+		PixelRatio: 1,
+		Colors:     testColors,
+	}
+}
+
+// column is the colors down the middle of bar i, from the top.
+func column(img *image.NRGBA, slot, i int) []color.NRGBA {
+	x := i*slot + slot/2
+	var col []color.NRGBA
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		col = append(col, img.NRGBAAt(x, y))
+	}
+	return col
+}
+
+// rows lists the rows of col in color c.
+func rows(col []color.NRGBA, c color.NRGBA) []int {
+	var ys []int
+	for y, got := range col {
+		if got == c {
+			ys = append(ys, y)
+		}
+	}
+	return ys
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBarChartGoalLine(t *testing.T) {
+	// The largest goal is the top of the plot, y 3; a goal half as big is
+	// halfway down the 40-pixel plot, and no goal draws no line.
+	img := testChart([]int{1, 1, 1}, []int{4, 2, 0}, ScaleGoal).Raster()
+	for i, want := range [][]int{{3}, {23}, nil} {
+		if got := rows(column(img, 10, i), testColors.Goal); !equalInts(got, want) {
+			t.Errorf("bar %d: goal line at rows %v, want %v", i, got, want)
+		}
+	}
+	// Goal lines span the whole slot, gaps included.
+	for x := 0; x < 10; x++ {
+		if got := img.NRGBAAt(x, 3); got != testColors.Goal {
+			t.Errorf("goal line pixel (%d, 3) = %v", x, got)
+		}
+	}
+}
+
+func TestBarChartGoalDrawnOverBar(t *testing.T) {
+	img := testChart([]int{4, 3}, []int{2}, ScaleAuto).Raster()
+	col := column(img, 10, 0)
+	// top 4: the bar fills y 3 to 42 and its goal line at y 23 is on it.
+	if got := rows(col, testColors.Goal); !equalInts(got, []int{23}) {
+		t.Errorf("goal line at rows %v, want [23]", got)
+	}
+	if got := col[22]; got != testColors.BarGoalMet {
+		t.Errorf("bar above the goal line = %v, want the goal met color", got)
+	}
+	if got := column(img, 10, 1)[30]; got != testColors.BarGoalMet {
+		t.Errorf("bar 1 of 3 with goal 2 = %v, want the goal met color", got)
+	}
+}
+
+func TestBarChartOverflow(t *testing.T) {
+	img := testChart([]int{10, 4, 3}, []int{4}, ScaleGoal).Raster()
+
+	over := column(img, 10, 0)
+	if got := rows(over, testColors.Overflow); !equalInts(got, []int{0, 1}) {
+		t.Errorf("overflow cap at rows %v, want [0 1] in the headroom strip", got)
+	}
+	if got := over[2]; got.A != 0 {
+		t.Errorf("row 2 between the cap and the bar = %v, want transparent", got)
+	}
+	// The cut-off bar stops at the top of the plot, under its goal line.
+	if got := rows(over, testColors.BarGoalMet); len(got) == 0 || got[0] != 4 || got[len(got)-1] != 42 {
+		t.Errorf("cut-off bar at rows %v, want 4 to 42", got)
+	}
+
+	// Meeting the goal exactly is not an overflow.
+	if got := rows(column(img, 10, 1), testColors.Overflow); len(got) != 0 {
+		t.Errorf("bar at its goal has an overflow cap at %v", got)
+	}
+	if got := rows(column(img, 10, 2), testColors.Bar); len(got) != 30 {
+		t.Errorf("bar 3 of 4 is %d pixels, want 30", len(got))
+	}
+}
+
+func TestBarChartScale(t *testing.T) {
+	tests := []struct {
+		scale  Scale
+		values []int
+		goals  []int
+		top    int
+	}{
+		{ScaleGoal, []int{10, 2}, []int{4}, 4},
+		{ScaleAuto, []int{10, 2}, []int{4}, 10},
+		{ScaleAuto, []int{1, 2}, []int{4}, 4},
+		{ScaleGoal, []int{6, 2}, []int{0}, 6}, // no goals: fit the values
+		{ScaleAuto, []int{0, 0}, nil, 1},
+		{ScaleGoal, []int{1, 1, 1}, []int{3, 8, 5}, 8},
+	}
+	for _, tt := range tests {
+		c := testChart(tt.values, tt.goals, tt.scale)
+		if got := c.top(); got != tt.top {
+			t.Errorf("%s %v goals %v: top = %d, want %d", tt.scale, tt.values, tt.goals, got, tt.top)
+		}
+	}
+
+	// Under auto scale the tallest bar reaches the top without a cap, and
+	// the goal is drawn in proportion.
+	img := testChart([]int{8}, []int{4}, ScaleAuto).Raster()
+	col := column(img, 10, 0)
+	if got := rows(col, testColors.Overflow); len(got) != 0 {
+		t.Errorf("auto scale drew an overflow cap at %v", got)
+	}
+	if got := rows(col, testColors.Goal); !equalInts(got, []int{23}) {
+		t.Errorf("goal line at rows %v, want [23]", got)
+	}
+	if col[3] != testColors.BarGoalMet {
+		t.Errorf("tallest bar does not reach the top of the plot: row 3 is %v", col[3])
+	}
+}
+
+func TestBarChartToday(t *testing.T) {
+	c := testChart([]int{1, 1, 1}, []int{4}, ScaleGoal)
+	c.Today = 1
+	img := c.Raster()
+	for x := 0; x < 30; x++ {
+		got := img.NRGBAAt(x, 1)
+		today := x >= 10 && x < 20
+		if today && got != testColors.Today {
+			t.Errorf("pixel (%d, 1) in today's slot = %v, want the today band", x, got)
+		}
+		if !today && got.A != 0 {
+			t.Errorf("pixel (%d, 1) outside today's slot = %v, want transparent", x, got)
+		}
+	}
+	// The band stops at the axis.
+	if got := img.NRGBAAt(15, 43); got != testColors.Axis {
+		t.Errorf("axis under today = %v", got)
+	}
+
+	c.Today = -1
+	if got := c.Raster().NRGBAAt(15, 1); got.A != 0 {
+		t.Errorf("Today -1 drew a band: %v", got)
+	}
+}
+
+func TestBarChartPixelRatio(t *testing.T) {
+	c := testChart([]int{2, 4}, []int{4}, ScaleGoal)
+	c.PixelRatio = 2
+	if w, h := c.Size(); w != 40 || h != 88 {
+		t.Errorf("Size = %dx%d, want 40x88", w, h)
+	}
+	img := c.Raster()
+	if got := img.Bounds(); got != image.Rect(0, 0, 40, 88) {
+		t.Fatalf("image is %v, want 40x88", got)
+	}
+	// Lines are two device pixels thick: the axis, and the goal line at
+	// the top of an 80-pixel plot under 6 pixels of headroom.
+	col := column(img, 20, 0)
+	if got := rows(col, testColors.Axis); !equalInts(got, []int{86, 87}) {
+		t.Errorf("axis at rows %v, want [86 87]", got)
+	}
+	if got := rows(col, testColors.Goal); !equalInts(got, []int{6, 7}) {
+		t.Errorf("goal line at rows %v, want [6 7]", got)
+	}
+
+	c.PixelRatio = 0
+	if w, h := c.Size(); w != 20 || h != 44 {
+		t.Errorf("PixelRatio 0: Size = %dx%d, want 20x44", w, h)
+	}
+}
+
+func TestParseScale(t *testing.T) {
+	for in, want := range map[string]Scale{"goal": ScaleGoal, "AUTO": ScaleAuto} {
+		if got, err := ParseScale(in); err != nil || got != want {
+			t.Errorf("ParseScale(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseScale("log"); err == nil {
+		t.Error("ParseScale(log) succeeded")
+	}
+}
+
+func TestLayoutBars(t *testing.T) {
+	values, goals := []int{1, 4, 12, 0}, []int{8, 8, 8, 0}
+
+	got := LayoutBars(values, goals, ScaleGoal, 80, 2)
+	want := []BarLevel{
+		{Height: 10, Goal: 80, HasGoal: true},
+		{Height: 40, Goal: 80, HasGoal: true},
+		{Height: 80, Goal: 80, HasGoal: true, GoalMet: true, Overflow: true},
+		{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("goal scale = %+v, want %+v", got, want)
+	}
+
+	// Auto fits the largest value; tiny values keep the minimum height.
+	got = LayoutBars(values, goals, ScaleAuto, 12, 2)
+	want = []BarLevel{
+		{Height: 2, Goal: 8, HasGoal: true},
+		{Height: 4, Goal: 8, HasGoal: true},
+		{Height: 12, Goal: 8, HasGoal: true, GoalMet: true},
+		{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("auto scale = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,168 @@
+package sparkline
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+)
+
+// CalendarDay is one day of a contribution calendar. Goal is the count that
+// fills a cell completely.
+type CalendarDay struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Count int    `json:"count"`
+	Goal  int    `json:"goal"`
+}
+
+// CalendarStyle selects how RenderCalendar draws cells.
+type CalendarStyle int
+
+const (
+	CalendarANSI    CalendarStyle = iota // 24-bit colored squares
+	CalendarBraille                      // plain braille, no color
+	CalendarKitty                        // Kitty graphics image
+)
+
+func ParseCalendarStyle(s string) (CalendarStyle, error) {
+	switch strings.ToLower(s) {
+	case "ansi", "color", "colour":
+		return CalendarANSI, nil
+	case "braille", "plain":
+		return CalendarBraille, nil
+	case "kitty":
+		return CalendarKitty, nil
+	}
+	return 0, fmt.Errorf("unknown calendar style %q (use ansi, braille or kitty)", s)
+}
+
+// CalendarLevels is the number of intensity levels above empty.
+const CalendarLevels = 4
+
+// calendarColor is the color of a level in p.
+func calendarColor(level int, p Palette) color.NRGBA {
+	if level == 0 {
+		return p.Empty
+	}
+	return p.At(float64(level-1) / float64(CalendarLevels-1))
+}
+
+// CalendarLevel buckets count into 0 to CalendarLevels. Only days that met
+// their goal reach the top level.
+func CalendarLevel(count, goal int) int {
+	switch {
+	case count <= 0:
+		return 0
+	case goal <= 0 || count >= goal:
+		return CalendarLevels
+	}
+	return min(1+count*(CalendarLevels-1)/goal, CalendarLevels-1)
+}
+
+// calendarRowLabels label every other row, Monday first.
+var calendarRowLabels = []string{"Mon", "", "Wed", "", "Fri", "", ""}
+
+// RenderCalendar draws days as a grid with a column per week and a row per
+// weekday, months labelled above, in the colors of p. days must start on a
+// Monday and be consecutive; the last week may be partial.
+func RenderCalendar(days []CalendarDay, style CalendarStyle, p Palette) string {
+	if len(days) == 0 {
+		return ""
+	}
+	weeks := (len(days) + 6) / 7
+
+	if style == CalendarKitty {
+		return calendarMonths(days, weeks, 0) + "\n" + calendarKitty(days, weeks, p)
+	}
+
+	var b strings.Builder
+	b.WriteString(calendarMonths(days, weeks, 4))
+	b.WriteString("\n")
+	for row := 0; row < 7; row++ {
+		fmt.Fprintf(&b, "%-3s ", calendarRowLabels[row])
+		for week := 0; week < weeks; week++ {
+			i := week*7 + row
+			if i >= len(days) {
+				break
+			}
+			b.WriteString(calendarCell(CalendarLevel(days[i].Count, days[i].Goal), style, p))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// CalendarLegend shows the levels from least to most for style.
+func CalendarLegend(style CalendarStyle, p Palette) string {
+	if style == CalendarKitty {
+		style = CalendarANSI
+	}
+	var cells strings.Builder
+	for level := 0; level <= CalendarLevels; level++ {
+		cells.WriteString(calendarCell(level, style, p))
+	}
+	return fmt.Sprintf("less %s more (top: goal met)", strings.TrimRight(cells.String(), " "))
+}
+
+func calendarCell(level int, style CalendarStyle, p Palette) string {
+	if style == CalendarBraille {
+		return string(brailleExt[level*(len(brailleExt)-1)/CalendarLevels]) + " "
+	}
+	c := calendarColor(level, p)
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm■\x1b[0m ", c.R, c.G, c.B)
+}
+
+// calendarMonths labels the first week of each month, two columns per week
+// after indent columns. Labels that would overlap are dropped.
+func calendarMonths(days []CalendarDay, weeks, indent int) string {
+	line := []rune(strings.Repeat(" ", indent+weeks*2))
+	next := 0
+	prev := ""
+	for week := 0; week < weeks; week++ {
+		t, err := time.Parse("2006-01-02", days[week*7].Date)
+		if err != nil {
+			continue
+		}
+		month := t.Format("Jan")
+		if month == prev {
+			continue
+		}
+		prev = month
+		pos := indent + week*2
+		if pos < next || pos+3 > len(line) {
+			continue
+		}
+		copy(line[pos:], []rune(month))
+		next = pos + 4
+	}
+	return strings.TrimRight(string(line), " ")
+}
+
+// Kitty cells are calendarCellPx square with a calendarGapPx gap, and the
+// image is scaled to two terminal columns per week so month labels line up.
+const (
+	calendarCellPx = 10
+	calendarGapPx  = 2
+)
+
+func calendarKitty(days []CalendarDay, weeks int, p Palette) string {
+	step := calendarCellPx + calendarGapPx
+	width, height := weeks*step, 7*step
+	pixels := make([]byte, width*height*4)
+
+	for i, day := range days {
+		c := calendarColor(CalendarLevel(day.Count, day.Goal), p)
+		x0, y0 := (i/7)*step, (i%7)*step
+		for y := y0; y < y0+calendarCellPx; y++ {
+			for x := x0; x < x0+calendarCellPx; x++ {
+				idx := (y*width + x) * 4
+				pixels[idx] = c.R
+				pixels[idx+1] = c.G
+				pixels[idx+2] = c.B
+				pixels[idx+3] = 255
+			}
+		}
+	}
+
+	return encodeKittyGraphicsCells(pixels, width, height, weeks*2, 7)
+}
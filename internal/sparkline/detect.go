@@ -0,0 +1,300 @@
+package sparkline
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/philleif/pomme/internal/paths"
+	"golang.org/x/sys/unix"
+)
+
+// queryTimeout is how long DetectRenderer waits for the terminal to answer.
+// Terminals that ignore a query still answer DA1, so this only runs out on
+// a terminal that answers nothing, or answers slowly, as over ssh.
+const queryTimeout = 300 * time.Millisecond
+
+// After a timeout, input is read and thrown away until the terminal has
+// been quiet for drainQuiet, for up to drainMax, so that late replies don't
+// reach the shell as if typed.
+const (
+	drainQuiet = 50 * time.Millisecond
+	drainMax   = time.Second
+)
+
+// detectTTL is how long a terminal's answer is remembered.
+const detectTTL = 24 * time.Hour
+
+var (
+	detectOnce sync.Once
+	detected   Renderer
+)
+
+// DetectRenderer picks the best renderer for the terminal on stdout:
+// environment variables first, then by asking the terminal over /dev/tty.
+// Inside tmux and anywhere graphics can't be detected it falls back to
+// blocks. The answer is cached for the process, and the terminal's answer
+// for its session in terminals.json in the runtime directory, so that it is
+// queried once rather than on every run.
+func DetectRenderer() Renderer {
+	detectOnce.Do(func() {
+		detected = detectRenderer()
+	})
+	return detected
+}
+
+func detectRenderer() Renderer {
+	if r, ok := rendererFromEnv(os.Getenv); ok {
+		return r
+	}
+	if !term.IsTerminal(os.Stdout.Fd()) {
+		return RendererBlocks
+	}
+
+	cache, key := detectCachePath(), terminalKey()
+	if r, ok := loadDetected(cache, key, time.Now()); ok {
+		return r
+	}
+	r := RendererBlocks
+	if reply, err := queryTerminal(); err == nil {
+		r = rendererFromReply(reply)
+	}
+	// A terminal that didn't answer is remembered too: asking again would
+	// only wait out the timeout again.
+	saveDetected(cache, key, r, time.Now())
+	return r
+}
+
+func detectCachePath() string {
+	dir, err := paths.RuntimeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "terminals.json")
+}
+
+// terminalKey identifies the terminal by the session it runs, which lasts
+// as long as the terminal window or ssh login, and what it calls itself.
+func terminalKey() string {
+	sid, _ := unix.Getsid(0)
+	return fmt.Sprintf("%d %s %s", sid, os.Getenv("TERM"), os.Getenv("TERM_PROGRAM"))
+}
+
+type detectEntry struct {
+	Renderer Renderer  `json:"renderer"`
+	At       time.Time `json:"at"`
+}
+
+// loadDetected returns the renderer saved for key at path, unless it is
+// older than detectTTL at now.
+func loadDetected(path, key string, now time.Time) (Renderer, bool) {
+	if path == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	var entries map[string]detectEntry
+	if json.Unmarshal(data, &entries) != nil {
+		return "", false
+	}
+	e, ok := entries[key]
+	if !ok || now.Sub(e.At) > detectTTL {
+		return "", false
+	}
+	if _, err := ParseRenderer(string(e.Renderer)); err != nil || e.Renderer == RendererAuto {
+		return "", false
+	}
+	return e.Renderer, true
+}
+
+// saveDetected records r for key at path, dropping entries past detectTTL.
+// The cache only saves time, so failing to write it is not an error.
+func saveDetected(path, key string, r Renderer, now time.Time) {
+	if path == "" {
+		return
+	}
+	entries := make(map[string]detectEntry)
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &entries)
+	}
+	for k, e := range entries {
+		if now.Sub(e.At) > detectTTL {
+			delete(entries, k)
+		}
+	}
+	entries[key] = detectEntry{Renderer: r, At: now}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if os.WriteFile(tmp, data, 0600) == nil {
+		os.Rename(tmp, path)
+	}
+}
+
+// rendererFromEnv recognizes terminals that announce themselves. tmux
+// passes graphics through only when configured to, so it gets blocks.
+func rendererFromEnv(getenv func(string) string) (Renderer, bool) {
+	termName := getenv("TERM")
+	switch {
+	case getenv("TMUX") != "" || strings.HasPrefix(termName, "screen") || strings.HasPrefix(termName, "tmux"):
+		return RendererBlocks, true
+	case getenv("KITTY_WINDOW_ID") != "" || getenv("GHOSTTY_RESOURCES_DIR") != "",
+		termName == "xterm-kitty", termName == "xterm-ghostty":
+		return RendererKitty, true
+	case getenv("TERM_PROGRAM") == "iTerm.app", getenv("LC_TERMINAL") == "iTerm2",
+		getenv("TERM_PROGRAM") == "WezTerm":
+		return RendererITerm2, true
+	case strings.Contains(termName, "foot"), strings.Contains(termName, "mlterm"),
+		strings.Contains(termName, "sixel"):
+		return RendererSixel, true
+	case termName == "dumb" || termName == "":
+		return RendererBlocks, true
+	}
+	return "", false
+}
+
+// Queries sent to the terminal: its name (XTGETTCAP TN), a kitty graphics
+// probe that draws nothing, and DA1, which every terminal answers and so
+// marks the end of the replies.
+const (
+	queryName  = "\x1bP+q544e\x1b\\"
+	queryKitty = "\x1b_Gi=31,s=1,v=1,a=q,t=d,f=24;AAAA\x1b\\"
+	queryDA1   = "\x1b[c"
+)
+
+// queryTerminal sends the queries on /dev/tty in raw mode and returns the
+// replies up to and including the DA1 answer.
+func queryTerminal() ([]byte, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer tty.Close()
+	fd := int(tty.Fd())
+
+	state, err := term.MakeRaw(tty.Fd())
+	if err != nil {
+		return nil, err
+	}
+	defer term.Restore(tty.Fd(), state)
+
+	if _, err := tty.WriteString(queryName + queryKitty + queryDA1); err != nil {
+		return nil, err
+	}
+
+	var reply []byte
+	buf := make([]byte, 256)
+	deadline := time.Now().Add(queryTimeout)
+	for !hasDA1(reply) {
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			drain(tty, fd)
+			return nil, os.ErrDeadlineExceeded
+		}
+		ready, err := waitInput(fd, wait)
+		if err != nil {
+			return nil, err
+		}
+		if !ready {
+			continue
+		}
+		n, err := tty.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		reply = append(reply, buf[:n]...)
+	}
+	return reply, nil
+}
+
+// drain reads and discards input on tty until it has been quiet for
+// drainQuiet, or for at most drainMax.
+func drain(tty *os.File, fd int) {
+	buf := make([]byte, 256)
+	end := time.Now().Add(drainMax)
+	for time.Now().Before(end) {
+		ready, err := waitInput(fd, drainQuiet)
+		if err != nil || !ready {
+			return
+		}
+		if _, err := tty.Read(buf); err != nil {
+			return
+		}
+	}
+}
+
+// waitInput waits up to d for fd to have input. It uses select, which,
+// unlike poll and kqueue on macOS, works on terminal devices everywhere.
+func waitInput(fd int, d time.Duration) (bool, error) {
+	if fd >= 8*int(unsafe.Sizeof(unix.FdSet{})) {
+		return false, fmt.Errorf("descriptor %d too large to wait on", fd)
+	}
+	for {
+		var set unix.FdSet
+		set.Set(fd)
+		tv := unix.NsecToTimeval(d.Nanoseconds())
+		n, err := unix.Select(fd+1, &set, nil, nil, &tv)
+		if err == unix.EINTR {
+			continue
+		}
+		return n > 0, err
+	}
+}
+
+// hasDA1 reports whether reply holds a complete DA1 answer, ESC [ ? … c.
+func hasDA1(reply []byte) bool {
+	i := bytes.Index(reply, []byte("\x1b[?"))
+	return i >= 0 && bytes.IndexByte(reply[i:], 'c') >= 0
+}
+
+func rendererFromReply(reply []byte) Renderer {
+	if bytes.Contains(reply, []byte("i=31;OK")) {
+		return RendererKitty
+	}
+
+	// XTGETTCAP answers ESC P 1 + r 544e=<hex name> ESC \.
+	if i := bytes.Index(reply, []byte("\x1bP1+r544e=")); i >= 0 {
+		rest := reply[i+len("\x1bP1+r544e="):]
+		if end := bytes.Index(rest, []byte("\x1b\\")); end >= 0 {
+			if name, err := hex.DecodeString(string(rest[:end])); err == nil {
+				if r, ok := rendererFromEnv(func(key string) string {
+					if key == "TERM" {
+						return strings.ToLower(string(name))
+					}
+					return ""
+				}); ok && r != RendererBlocks {
+					return r
+				}
+				if strings.Contains(strings.ToLower(string(name)), "wezterm") {
+					return RendererITerm2
+				}
+			}
+		}
+	}
+
+	// DA1 lists 4 among its attributes when the terminal does sixel.
+	if i := bytes.Index(reply, []byte("\x1b[?")); i >= 0 {
+		rest := reply[i+3:]
+		if end := bytes.IndexByte(rest, 'c'); end >= 0 {
+			for _, attr := range strings.Split(string(rest[:end]), ";") {
+				if attr == "4" {
+					return RendererSixel
+				}
+			}
+		}
+	}
+	return RendererBlocks
+}
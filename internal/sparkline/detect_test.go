@@ -0,0 +1,106 @@
+package sparkline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDetectCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terminals.json")
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	if _, ok := loadDetected(path, "42 xterm-256color ", now); ok {
+		t.Fatal("found a renderer before any was saved")
+	}
+	saveDetected(path, "42 xterm-256color ", RendererSixel, now)
+	saveDetected(path, "43 xterm-256color ", RendererBlocks, now.Add(-detectTTL-time.Minute))
+
+	if r, ok := loadDetected(path, "42 xterm-256color ", now.Add(time.Hour)); !ok || r != RendererSixel {
+		t.Errorf("loaded %q, %v; want sixel", r, ok)
+	}
+	if _, ok := loadDetected(path, "42 xterm-256color ", now.Add(detectTTL+time.Minute)); ok {
+		t.Error("loaded an entry older than detectTTL")
+	}
+	if _, ok := loadDetected(path, "43 xterm-256color ", now); ok {
+		t.Error("loaded an expired entry")
+	}
+
+	// Saving again drops expired entries and keeps the rest.
+	saveDetected(path, "44 foot ", RendererSixel, now)
+	if r, ok := loadDetected(path, "42 xterm-256color ", now); !ok || r != RendererSixel {
+		t.Errorf("entry 42 lost: %q, %v", r, ok)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"42 xterm-256color ": {"renderer": "auto", "at": "2026-10-18T12:00:00Z"}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if r, ok := loadDetected(path, "42 xterm-256color ", now); ok {
+		t.Errorf("loaded %q, which is not a renderer to draw with", r)
+	}
+	if err := os.WriteFile(path, []byte("not json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loadDetected(path, "42 xterm-256color ", now); ok {
+		t.Error("loaded from a corrupt cache")
+	}
+	saveDetected(path, "42 xterm-256color ", RendererKitty, now)
+	if r, ok := loadDetected(path, "42 xterm-256color ", now); !ok || r != RendererKitty {
+		t.Errorf("corrupt cache not replaced: %q, %v", r, ok)
+	}
+}
+
+// Replies arriving after the timeout are read and thrown away rather than
+// left for the shell.
+func TestDrain(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if _, err := w.WriteString("\x1bP1+r544e=666f6f74\x1b\\\x1b[?62;4c"); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		time.Sleep(drainQuiet / 2)
+		w.WriteString("\x1b_Gi=31;OK\x1b\\")
+	}()
+
+	fd := int(r.Fd())
+	drain(r, fd)
+	ready, err := waitInput(fd, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ready {
+		t.Error("input left after draining")
+	}
+
+	start := time.Now()
+	drain(r, fd)
+	if elapsed := time.Since(start); elapsed > drainQuiet+50*time.Millisecond {
+		t.Errorf("draining quiet input took %v", elapsed)
+	}
+}
+
+func TestRendererFromReply(t *testing.T) {
+	tests := []struct {
+		reply string
+		want  Renderer
+	}{
+		{"\x1b_Gi=31;OK\x1b\\\x1b[?62;c", RendererKitty},
+		{"\x1bP1+r544e=666f6f74\x1b\\\x1b[?62;c", RendererSixel},       // foot
+		{"\x1bP1+r544e=57657a5465726d\x1b\\\x1b[?62c", RendererITerm2}, // WezTerm
+		{"\x1b[?62;4;22c", RendererSixel},
+		{"\x1b[?1;2c", RendererBlocks},
+		{"", RendererBlocks},
+	}
+	for _, tt := range tests {
+		if got := rendererFromReply([]byte(tt.reply)); got != tt.want {
+			t.Errorf("rendererFromReply(%q) = %s, want %s", tt.reply, got, tt.want)
+		}
+	}
+}
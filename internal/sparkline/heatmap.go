@@ -0,0 +1,102 @@
+package sparkline
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HeatmapStyle selects how RenderHeatmap shades cells.
+type HeatmapStyle int
+
+const (
+	HeatmapBlock   HeatmapStyle = iota // ░▒▓█ shading
+	HeatmapBraille                     // braille bars, like the sparklines
+	HeatmapColor                       // truecolor background cells
+)
+
+func ParseHeatmapStyle(s string) (HeatmapStyle, error) {
+	switch strings.ToLower(s) {
+	case "block":
+		return HeatmapBlock, nil
+	case "braille":
+		return HeatmapBraille, nil
+	case "color", "colour", "truecolor":
+		return HeatmapColor, nil
+	}
+	return 0, fmt.Errorf("unknown heatmap style %q (use block, braille or color)", s)
+}
+
+var shades = []rune{' ', '░', '▒', '▓', '█'}
+
+// RenderHeatmap draws rows of counts as a grid two columns per cell, each
+// row preceded by its label and every cell shaded relative to the largest
+// count. colLabels, when given, label every third column above the grid.
+// HeatmapColor shades with p.
+func RenderHeatmap(rows [][]int, rowLabels, colLabels []string, style HeatmapStyle, p Palette) string {
+	most := 0
+	labelWidth := 0
+	for i, row := range rows {
+		for _, v := range row {
+			most = max(most, v)
+		}
+		if i < len(rowLabels) {
+			labelWidth = max(labelWidth, len([]rune(rowLabels[i])))
+		}
+	}
+
+	var b strings.Builder
+	if len(colLabels) > 0 {
+		header := strings.Repeat(" ", labelWidth+1)
+		for i := 0; i < len(colLabels); i += 3 {
+			header += fmt.Sprintf("%-6s", colLabels[i])
+		}
+		b.WriteString(strings.TrimRight(header, " ") + "\n")
+	}
+
+	for i, row := range rows {
+		label := ""
+		if i < len(rowLabels) {
+			label = rowLabels[i]
+		}
+		fmt.Fprintf(&b, "%-*s ", labelWidth, label)
+		for _, v := range row {
+			b.WriteString(heatCell(v, most, style, p))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// HeatmapLegend shows the shades from least to most for style.
+func HeatmapLegend(most int, style HeatmapStyle, p Palette) string {
+	var cells strings.Builder
+	for level := 1; level <= 4; level++ {
+		cells.WriteString(heatCell(level, 4, style, p))
+	}
+	return fmt.Sprintf("less %s more (max %d)", strings.TrimRight(cells.String(), " "), most)
+}
+
+func heatCell(v, most int, style HeatmapStyle, p Palette) string {
+	switch style {
+	case HeatmapBraille:
+		return string(brailleExt[level(v, most, len(brailleExt)-1)]) + " "
+	case HeatmapColor:
+		c := p.Empty
+		if v > 0 && most > 0 {
+			c = p.At(float64(v) / float64(most))
+		}
+		return fmt.Sprintf("\x1b[48;2;%d;%d;%dm  \x1b[0m", c.R, c.G, c.B)
+	}
+	return strings.Repeat(string(shades[level(v, most, len(shades)-1)]), 2)
+}
+
+// level scales v to 0..levels, rounding up so any non-zero count shows.
+func level(v, most, levels int) int {
+	if v <= 0 || most <= 0 {
+		return 0
+	}
+	if v >= most {
+		return levels
+	}
+	return (v*levels + most - 1) / most
+}
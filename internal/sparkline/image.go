@@ -0,0 +1,189 @@
+package sparkline
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"strings"
+)
+
+// Renderer is how GenerateGraph puts a graph on the terminal.
+type Renderer string
+
+const (
+	RendererAuto   Renderer = "auto" // DetectRenderer decides
+	RendererKitty  Renderer = "kitty"
+	RendererSixel  Renderer = "sixel"
+	RendererITerm2 Renderer = "iterm2"
+	RendererBlocks Renderer = "blocks" // text fallback, works everywhere
+)
+
+var renderers = []Renderer{RendererAuto, RendererKitty, RendererSixel, RendererITerm2, RendererBlocks}
+
+func ParseRenderer(s string) (Renderer, error) {
+	for _, r := range renderers {
+		if string(r) == strings.ToLower(s) {
+			return r, nil
+		}
+	}
+	names := make([]string, len(renderers))
+	for i, r := range renderers {
+		names[i] = string(r)
+	}
+	return "", fmt.Errorf("unknown renderer %q (use %s)", s, strings.Join(names, ", "))
+}
+
+// GenerateGraph draws c with the given renderer; RendererAuto is resolved
+// with DetectRenderer. The blocks fallback is one line of block characters
+// spaced like GenerateBrailleSpaced.
+//
+// Kitty and sixel show one image pixel per screen pixel, so a chart with a
+// PixelRatio of 2 looks right on a HiDPI screen. iTerm2 is told the chart's
+// logical size and scales it itself.
+func GenerateGraph(c BarChart, r Renderer) string {
+	if len(c.Values) == 0 {
+		return ""
+	}
+	if r == RendererAuto {
+		r = DetectRenderer()
+	}
+	if r == RendererBlocks {
+		return generateBlocksSpaced(c.Values, c.top())
+	}
+
+	w, h := c.Size()
+	pixels := c.Raster().Pix
+	switch r {
+	case RendererSixel:
+		return encodeSixel(pixels, w, h)
+	case RendererITerm2:
+		return encodeITerm2(pixels, w, h, c.Width, c.Height)
+	}
+	return encodeKittyGraphics(pixels, w, h)
+}
+
+func generateBlocksSpaced(values []int, maxVal int) string {
+	if maxVal <= 0 {
+		maxVal = 12
+	}
+	var b strings.Builder
+	for i, v := range values {
+		switch {
+		case v <= 0:
+			b.WriteRune(' ')
+		case v >= maxVal:
+			b.WriteRune(blocks[len(blocks)-1])
+		default:
+			b.WriteRune(blocks[v*(len(blocks)-1)/maxVal])
+		}
+		if i < len(values)-1 {
+			b.WriteString("  ")
+		}
+	}
+	return b.String()
+}
+
+// encodeITerm2 sends pixels as an inline PNG using iTerm2's OSC 1337 file
+// protocol, which WezTerm, mintty and Konsole also understand. It is shown
+// at showWidth by showHeight pixels.
+func encodeITerm2(pixels []byte, width, height, showWidth, showHeight int) string {
+	img := &image.NRGBA{Pix: pixels, Stride: width * 4, Rect: image.Rect(0, 0, width, height)}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%dpx;height=%dpx;preserveAspectRatio=1:%s\a",
+		buf.Len(), showWidth, showHeight, base64.StdEncoding.EncodeToString(buf.Bytes()))
+}
+
+// encodeSixel encodes pixels as DEC sixel graphics. Transparent pixels are
+// left as the terminal background. Images with more than 256 colors are
+// reduced to a 6×6×6 color cube.
+func encodeSixel(pixels []byte, width, height int) string {
+	palette, index := sixelPalette(pixels)
+
+	var b strings.Builder
+	// P2=1: pixels not drawn keep the background.
+	fmt.Fprintf(&b, "\x1bP0;1;0q\"1;1;%d;%d", width, height)
+	for i, c := range palette {
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, int(c[0])*100/255, int(c[1])*100/255, int(c[2])*100/255)
+	}
+
+	row := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		for ci := range palette {
+			used := false
+			for x := 0; x < width; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < height; dy++ {
+					p := ((band+dy)*width + x) * 4
+					if pixels[p+3] >= 128 && index(pixels[p:p+3]) == ci {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = 63 + bits
+				used = used || bits != 0
+			}
+			if !used {
+				continue
+			}
+			fmt.Fprintf(&b, "#%d", ci)
+			writeSixelRow(&b, row)
+			b.WriteByte('$')
+		}
+		b.WriteByte('-')
+	}
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeSixelRow writes one color's sixels for a band, run-length encoded.
+func writeSixelRow(b *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(b, "!%d%c", n, row[i])
+		} else {
+			b.Write(row[i:j])
+		}
+		i = j
+	}
+}
+
+// sixelPalette collects the opaque colors of pixels, and returns a function
+// mapping a pixel's RGB to its palette index.
+func sixelPalette(pixels []byte) ([][3]byte, func([]byte) int) {
+	seen := make(map[[3]byte]int)
+	var palette [][3]byte
+	for p := 0; p+3 < len(pixels); p += 4 {
+		if pixels[p+3] < 128 {
+			continue
+		}
+		c := [3]byte{pixels[p], pixels[p+1], pixels[p+2]}
+		if _, ok := seen[c]; !ok {
+			seen[c] = len(palette)
+			palette = append(palette, c)
+		}
+	}
+	if len(palette) <= 256 {
+		return palette, func(rgb []byte) int { return seen[[3]byte{rgb[0], rgb[1], rgb[2]}] }
+	}
+
+	palette = palette[:0]
+	for r := 0; r < 6; r++ {
+		for g := 0; g < 6; g++ {
+			for bl := 0; bl < 6; bl++ {
+				palette = append(palette, [3]byte{byte(r * 51), byte(g * 51), byte(bl * 51)})
+			}
+		}
+	}
+	level := func(v byte) int { return (int(v) + 25) / 51 }
+	return palette, func(rgb []byte) int {
+		return level(rgb[0])*36 + level(rgb[1])*6 + level(rgb[2])
+	}
+}
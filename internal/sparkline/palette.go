@@ -0,0 +1,25 @@
+package sparkline
+
+import "image/color"
+
+// Palette colors the calendar and heatmap: Empty for no pomodoros, shading
+// from Low for the fewest to High for the most.
+type Palette struct {
+	Empty color.NRGBA
+	Low   color.NRGBA
+	High  color.NRGBA
+}
+
+func DefaultPalette() Palette {
+	return Palette{
+		Empty: color.NRGBA{48, 48, 48, 255},
+		Low:   color.NRGBA{110, 45, 38, 255},
+		High:  color.NRGBA{255, 99, 71, 255},
+	}
+}
+
+// At blends Low towards High by f (0 to 1).
+func (p Palette) At(f float64) color.NRGBA {
+	blend := func(a, b uint8) uint8 { return uint8(float64(a) + f*(float64(b)-float64(a)) + 0.5) }
+	return color.NRGBA{blend(p.Low.R, p.High.R), blend(p.Low.G, p.High.G), blend(p.Low.B, p.High.B), 255}
+}
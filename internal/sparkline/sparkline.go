@@ -32,9 +32,6 @@ var brailleExt = []rune{
 	'⣿', // 8 - full
 }
 
-// Subscript digits for compact display
-var subscripts = []string{"₀", "₁", "₂", "₃", "₄", "₅", "₆", "₇", "₈", "₉", "₁₀", "₁₁", "₁₂"}
-
 type Style int
 
 const (
@@ -50,8 +47,9 @@ type Options struct {
 	ShowValues   bool
 	HighlightIdx int // -1 for no highlight (today is typically last index)
 	ShowGoal     bool
-	Width        int // For Kitty graphics: pixel width
-	Height       int // For Kitty graphics: pixel height
+	Width        int       // For Kitty graphics: pixel width
+	Height       int       // For Kitty graphics: pixel height
+	Colors       BarColors // For Kitty graphics
 }
 
 func DefaultOptions() Options {
@@ -64,6 +62,7 @@ func DefaultOptions() Options {
 		ShowGoal:     false,
 		Width:        140, // 7 days * 20px
 		Height:       20,
+		Colors:       DefaultBarColors(),
 	}
 }
 
@@ -131,18 +130,37 @@ func GenerateBraille(values []int, maxVal int) string {
 
 // GenerateBrailleSpaced creates a spaced braille sparkline for alignment with 3-char columns
 func GenerateBrailleSpaced(values []int, maxVal int) string {
+	goals := make([]int, len(values))
+	for i := range goals {
+		goals[i] = maxVal
+	}
+	return GenerateBrailleSpacedGoals(values, goals)
+}
+
+// GenerateBrailleSpacedGoals is GenerateBrailleSpaced with each value scaled
+// to its own day's goal. Days without a goal (days off) use the largest goal.
+func GenerateBrailleSpacedGoals(values, goals []int) string {
 	if len(values) == 0 {
 		return ""
 	}
 
-	if maxVal <= 0 {
-		maxVal = 12
+	fallback := 0
+	for _, g := range goals {
+		fallback = max(fallback, g)
+	}
+	if fallback <= 0 {
+		fallback = 12
 	}
 
 	var result strings.Builder
 	levels := len(brailleExt) - 1
 
 	for i, v := range values {
+		maxVal := fallback
+		if i < len(goals) && goals[i] > 0 {
+			maxVal = goals[i]
+		}
+
 		var char rune
 		if v <= 0 {
 			char = brailleExt[0]
@@ -178,7 +196,7 @@ func GenerateEnhanced(values []int, opts Options) Output {
 	case StyleBraille:
 		out.Sparkline = GenerateBraille(values, maxVal)
 	case StyleKittyGraphics:
-		out.Sparkline = GenerateKittyGraphics(values, maxVal, opts.Width, opts.Height)
+		out.Sparkline = GenerateKittyGraphics(values, maxVal, opts.Width, opts.Height, opts.Colors)
 	default:
 		out.Sparkline = Generate(values, maxVal)
 	}
@@ -217,19 +235,9 @@ func FromDayStats(intervals []int, goal int) string {
 	return GenerateBraille(intervals, goal)
 }
 
-func Subscript(n int) string {
-	if n >= 0 && n < len(subscripts) {
-		return subscripts[n]
-	}
-	if n >= 10 {
-		return fmt.Sprintf("₁%s", subscripts[n%10])
-	}
-	return fmt.Sprintf("%d", n)
-}
-
 // GenerateKittyGraphics creates a pixel-based sparkline using Kitty graphics protocol
-// This works in Ghostty and Kitty terminals
-func GenerateKittyGraphics(values []int, maxVal, width, height int) string {
+// This works in Ghostty and Kitty terminals; colors are usually a theme's BarColors
+func GenerateKittyGraphics(values []int, maxVal, width, height int, colors BarColors) string {
 	if len(values) == 0 {
 		return ""
 	}
@@ -238,70 +246,20 @@ func GenerateKittyGraphics(values []int, maxVal, width, height int) string {
 		maxVal = 12
 	}
 
-	barWidth := width / len(values)
-	if barWidth < 2 {
-		barWidth = 2
-	}
-
-	// Create RGBA pixel data
-	pixels := make([]byte, width*height*4)
-
-	// Fill with transparent background
-	for i := 0; i < len(pixels); i += 4 {
-		pixels[i] = 0   // R
-		pixels[i+1] = 0 // G
-		pixels[i+2] = 0 // B
-		pixels[i+3] = 0 // A (transparent)
-	}
-
-	// Draw bars
-	for i, v := range values {
-		barHeight := 0
-		if v > 0 {
-			barHeight = (v * height) / maxVal
-			if barHeight > height {
-				barHeight = height
-			}
-		}
-
-		x0 := i * barWidth
-		x1 := x0 + barWidth - 1
-		if x1 >= width {
-			x1 = width - 1
-		}
-
-		// Color: tomato red for work intervals
-		r, g, b := byte(255), byte(99), byte(71)
-
-		for y := height - barHeight; y < height; y++ {
-			for x := x0; x < x1; x++ {
-				idx := (y*width + x) * 4
-				if idx+3 < len(pixels) {
-					pixels[idx] = r
-					pixels[idx+1] = g
-					pixels[idx+2] = b
-					pixels[idx+3] = 255 // opaque
-				}
-			}
-		}
-
-		// Draw goal line (at maxVal height) - subtle gray
-		goalY := 0 // top of the sparkline area
-		for x := x0; x < x1; x++ {
-			idx := (goalY*width + x) * 4
-			if idx+3 < len(pixels) {
-				pixels[idx] = 100
-				pixels[idx+1] = 100
-				pixels[idx+2] = 100
-				pixels[idx+3] = 128
-			}
-		}
-	}
-
-	return encodeKittyGraphics(pixels, width, height)
+	chart := NewBarChart(values, maxVal, width, height)
+	chart.Colors = colors
+	img := chart.Raster()
+	return encodeKittyGraphics(img.Pix, width, height)
 }
 
 func encodeKittyGraphics(pixels []byte, width, height int) string {
+	return encodeKittyGraphicsCells(pixels, width, height, 0, 0)
+}
+
+// encodeKittyGraphicsCells is encodeKittyGraphics scaled to fill cols by
+// rows terminal cells, so the image lines up with text. Zero keeps the
+// image's own size.
+func encodeKittyGraphicsCells(pixels []byte, width, height, cols, rows int) string {
 	encoded := base64.StdEncoding.EncodeToString(pixels)
 
 	var buf bytes.Buffer
@@ -329,7 +287,11 @@ func encodeKittyGraphics(pixels []byte, width, height int) string {
 		
 		if i == 0 {
 			// First chunk: include all parameters
-			buf.WriteString(fmt.Sprintf("\x1b_Ga=T,f=32,s=%d,v=%d,m=%d;%s\x1b\\", width, height, m, chunk))
+			var placement string
+			if cols > 0 && rows > 0 {
+				placement = fmt.Sprintf(",c=%d,r=%d", cols, rows)
+			}
+			buf.WriteString(fmt.Sprintf("\x1b_Ga=T,f=32,s=%d,v=%d%s,m=%d;%s\x1b\\", width, height, placement, m, chunk))
 		} else {
 			// Continuation chunk
 			buf.WriteString(fmt.Sprintf("\x1b_Gm=%d;%s\x1b\\", m, chunk))
@@ -338,9 +300,3 @@ func encodeKittyGraphics(pixels []byte, width, height int) string {
 
 	return buf.String()
 }
-
-// CompactStatus generates a compact status line for menu bar / tmux
-// Format: "🍅 18:32 ⣀⣤⣶⣿⣷⣄⣿₈"
-func CompactStatus(icon, time, sparkline string, todayCount int) string {
-	return fmt.Sprintf("%s %s %s%s", icon, time, sparkline, Subscript(todayCount))
-}
@@ -0,0 +1,47 @@
+package sparkline
+
+import (
+	"encoding/base64"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// kittyPixels joins the base64 payloads of a Kitty graphics sequence.
+func kittyPixels(t *testing.T, seq string) []byte {
+	t.Helper()
+	var encoded strings.Builder
+	for _, chunk := range strings.Split(seq, "\x1b\\") {
+		if _, payload, ok := strings.Cut(chunk, ";"); ok {
+			encoded.WriteString(payload)
+		}
+	}
+	pixels, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pixels
+}
+
+func TestGenerateKittyGraphicsColors(t *testing.T) {
+	colors := DefaultBarColors()
+	colors.BarGoalMet = color.NRGBA{0, 114, 178, 255}
+	width, height := 20, 44
+
+	pixels := kittyPixels(t, GenerateKittyGraphics([]int{4, 1}, 4, width, height, colors))
+	if len(pixels) != width*height*4 {
+		t.Fatalf("%d bytes, want %d", len(pixels), width*height*4)
+	}
+	// Bottom of the first bar, which meets its goal.
+	i := ((height-2)*width + 5) * 4
+	if got := (color.NRGBA{pixels[i], pixels[i+1], pixels[i+2], pixels[i+3]}); got != colors.BarGoalMet {
+		t.Errorf("bar meeting its goal is %v, want %v", got, colors.BarGoalMet)
+	}
+
+	opts := DefaultOptions()
+	opts.Style, opts.Width, opts.Height, opts.MaxVal = StyleKittyGraphics, width, height, 4
+	opts.Colors = colors
+	if got := GenerateEnhanced([]int{4, 1}, opts).Sparkline; got != GenerateKittyGraphics([]int{4, 1}, 4, width, height, colors) {
+		t.Error("GenerateEnhanced does not draw with opts.Colors")
+	}
+}
@@ -0,0 +1,190 @@
+// Package statusline formats the timer's status for status bars: tmux,
+// polybar, waybar, i3blocks and the like. A format is a Go text/template
+// over Status, or the name of one of the Presets.
+package statusline
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Status is what a format template sees. Use it as {{.Field}}, e.g.
+// "{{.Icon}} {{.Remaining}} {{.Progress}}".
+type Status struct {
+	// State is "work" or "break" while running, else "paused", "idle" or,
+	// when the daemon can't be reached, "offline".
+	State string
+	// Phase is "work", "short_break" or "long_break".
+	Phase            string
+	Icon             string // 🍅 ☕ ⏸ ⏹
+	ASCIIIcon        string // W B P - for terminals without emoji
+	Remaining        string // mm:ss
+	RemainingSeconds int
+
+	Today    int // pomodoros done today
+	Goal     int // today's goal; 0 on a day off
+	DayOff   bool
+	Progress string // Today/Goal, e.g. "3/8" or "3/off"
+	Percent  int    // Today as a percentage of Goal, at most 100
+	GoalMet  bool
+
+	Sparkline     string // the last seven days in braille, today last
+	Week          []int  // pomodoros per day for the last seven days
+	Streak        int    // days in a row the goal was met
+	Task          string // current task, if any
+	Interruptions string // ' and - marks for the interval in progress
+
+	// Colors are the theme's colors by name ("accent", "good", ...), plus
+	// "state" for the color of State. Empty under NO_COLOR.
+	Colors map[string]string
+}
+
+// Tooltip describes s over several lines, for status bars with tooltips.
+func (s Status) Tooltip() string {
+	if s.State == "offline" {
+		return "Pomme is not running"
+	}
+	state := stateNames[s.State]
+	if s.State != "idle" {
+		state += ": " + s.Remaining + " left"
+	}
+	lines := []string{state, "Today: " + s.Progress}
+	if s.Streak > 0 {
+		lines = append(lines, fmt.Sprintf("Streak: %d days", s.Streak))
+	}
+	if s.Task != "" {
+		lines = append(lines, "Task: "+s.Task)
+	}
+	return strings.Join(lines, "\n")
+}
+
+var stateNames = map[string]string{
+	"work":   "Working",
+	"break":  "On a break",
+	"paused": "Paused",
+	"idle":   "Stopped",
+}
+
+// State names the state of a timer for Status.State from its state and
+// phase as the daemon reports them.
+func State(timerState, phase string) string {
+	switch {
+	case timerState == "paused" || timerState == "idle":
+		return timerState
+	case phase == "work":
+		return "work"
+	default:
+		return "break"
+	}
+}
+
+var icons = map[string][2]string{
+	"work":    {"🍅", "W"},
+	"break":   {"☕", "B"},
+	"paused":  {"⏸", "P"},
+	"idle":    {"⏹", "-"},
+	"offline": {"⏹", "-"},
+}
+
+// Icon is the emoji for a Status.State.
+func Icon(state string) string {
+	return icons[state][0]
+}
+
+// ASCIIIcon is the letter for a Status.State.
+func ASCIIIcon(state string) string {
+	return icons[state][1]
+}
+
+// Offline is the status shown when the daemon isn't running.
+func Offline() Status {
+	return Status{State: "offline", Icon: Icon("offline"), ASCIIIcon: ASCIIIcon("offline"), Remaining: "--:--", Progress: "-"}
+}
+
+// Presets are the built-in formats.
+var Presets = map[string]string{
+	"default":   `{{.Icon}} {{.Remaining}}{{with .Sparkline}} {{.}}{{end}}{{if ne .State "offline"}}{{subscript .Today}}{{end}}`,
+	"ascii":     `{{.ASCIIIcon}} {{.Remaining}} {{.Progress}}`,
+	"simplebar": `{{if eq .State "idle"}}⏹{{else}}{{.Icon}}{{end}} {{.Remaining}}{{if ne .State "offline"}} {{subscript .Today}}{{end}}`,
+	"tmux":      `{{tmux .Colors.state (print .Icon " " .Remaining)}}{{with .Sparkline}} {{tmux $.Colors.good .}}{{end}}{{if ne .State "offline"}}{{tmux .Colors.accent (subscript .Today)}}{{end}}`,
+	"polybar":   `{{polybar .Colors.state (print .Icon " " .Remaining)}} {{polybar .Colors.accent .Progress}}`,
+	"waybar":    `{"text": {{json (print .Icon " " .Remaining " " .Progress)}}, "alt": {{json .State}}, "tooltip": {{json .Tooltip}}, "class": {{json .State}}, "percentage": {{.Percent}}}`,
+	"i3blocks":  `{"full_text": {{json (print .Icon " " .Remaining " " .Progress)}}, "short_text": {{json (print .Icon " " .Remaining)}}{{with .Colors.state}}, "color": {{json .}}{{end}}}`,
+}
+
+// PresetNames lists the presets, sorted.
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var funcs = template.FuncMap{
+	"subscript": Subscript,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// tmux and polybar wrap text in color markup; no color leaves it as is.
+	"tmux": func(color, s string) string {
+		if color == "" || s == "" {
+			return s
+		}
+		return "#[fg=" + strings.ToLower(color) + "]" + s + "#[fg=default]"
+	},
+	"polybar": func(color, s string) string {
+		if color == "" || s == "" {
+			return s
+		}
+		return "%{F" + strings.ToLower(color) + "}" + s + "%{F-}"
+	},
+}
+
+// Parse parses format, a preset name or a template.
+func Parse(format string) (*template.Template, error) {
+	if preset, ok := Presets[format]; ok {
+		format = preset
+	}
+	t, err := template.New("status").Funcs(funcs).Option("missingkey=zero").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status format: %w", err)
+	}
+	return t, nil
+}
+
+// Format renders s with format, a preset name or a template.
+func Format(s Status, format string) (string, error) {
+	t, err := Parse(format)
+	if err != nil {
+		return "", err
+	}
+	if s.Colors == nil {
+		s.Colors = map[string]string{}
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, s); err != nil {
+		return "", fmt.Errorf("status format: %w", err)
+	}
+	return b.String(), nil
+}
+
+// Subscript writes n in subscript digits, e.g. ₁₂.
+func Subscript(n int) string {
+	digits := []rune("₀₁₂₃₄₅₆₇₈₉")
+	s := fmt.Sprint(n)
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(digits[r-'0'])
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package statusline
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func working() Status {
+	return Status{
+		State:            "work",
+		Phase:            "work",
+		Icon:             Icon("work"),
+		ASCIIIcon:        ASCIIIcon("work"),
+		Remaining:        "12:34",
+		RemainingSeconds: 754,
+		Today:            3,
+		Goal:             8,
+		Progress:         "3/8",
+		Percent:          37,
+		Sparkline:        "⣀⣤⣶",
+		Streak:           2,
+		Task:             `Write "docs"` + "\nand tests",
+		Colors:           map[string]string{"state": "#00FF00", "good": "#73F59F", "accent": "#FF6347"},
+	}
+}
+
+func TestPresets(t *testing.T) {
+	plain := working()
+	plain.Colors = nil
+
+	tests := []struct {
+		preset string
+		status Status
+		want   string
+	}{
+		{"default", working(), "🍅 12:34 ⣀⣤⣶₃"},
+		{"ascii", working(), "W 12:34 3/8"},
+		{"simplebar", working(), "🍅 12:34 ₃"},
+		{"tmux", working(), "#[fg=#00ff00]🍅 12:34#[fg=default] #[fg=#73f59f]⣀⣤⣶#[fg=default]#[fg=#ff6347]₃#[fg=default]"},
+		{"tmux", plain, "🍅 12:34 ⣀⣤⣶₃"},
+		{"polybar", working(), "%{F#00ff00}🍅 12:34%{F-} %{F#ff6347}3/8%{F-}"},
+		{"polybar", plain, "🍅 12:34 3/8"},
+		{"waybar", working(), `{"text": "🍅 12:34 3/8", "alt": "work", "tooltip": "Working: 12:34 left\nToday: 3/8\nStreak: 2 days\nTask: Write \"docs\"\nand tests", "class": "work", "percentage": 37}`},
+		{"i3blocks", working(), `{"full_text": "🍅 12:34 3/8", "short_text": "🍅 12:34", "color": "#00FF00"}`},
+		{"i3blocks", plain, `{"full_text": "🍅 12:34 3/8", "short_text": "🍅 12:34"}`},
+		{"default", Offline(), "⏹ --:--"},
+		{"simplebar", Offline(), "⏹ --:--"},
+		{"tmux", Offline(), "⏹ --:--"},
+		{"simplebar", Status{State: "idle", Icon: "🍅", Remaining: "30:00", Today: 2}, "⏹ 30:00 ₂"},
+		{"ascii", Offline(), "- --:-- -"},
+	}
+	for _, tt := range tests {
+		got, err := Format(tt.status, tt.preset)
+		if err != nil {
+			t.Errorf("%s: %v", tt.preset, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.preset, got, tt.want)
+		}
+	}
+}
+
+func TestPresetsJSON(t *testing.T) {
+	for _, preset := range []string{"waybar", "i3blocks"} {
+		for _, s := range []Status{working(), Offline()} {
+			got, err := Format(s, preset)
+			if err != nil {
+				t.Fatalf("%s: %v", preset, err)
+			}
+			var v map[string]any
+			if err := json.Unmarshal([]byte(got), &v); err != nil {
+				t.Errorf("%s %s: invalid JSON %q: %v", preset, s.State, got, err)
+			}
+		}
+	}
+
+	got, _ := Format(working(), "waybar")
+	var v struct{ Tooltip string }
+	if err := json.Unmarshal([]byte(got), &v); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(v.Tooltip, "Task: "+working().Task) {
+		t.Errorf("tooltip = %q, want the task kept as is", v.Tooltip)
+	}
+}
+
+func TestPresetNames(t *testing.T) {
+	for _, name := range PresetNames() {
+		if _, err := Parse(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestState(t *testing.T) {
+	tests := []struct{ timer, phase, want string }{
+		{"running", "work", "work"},
+		{"running", "short_break", "break"},
+		{"running", "long_break", "break"},
+		{"paused", "work", "paused"},
+		{"idle", "work", "idle"},
+	}
+	for _, tt := range tests {
+		if got := State(tt.timer, tt.phase); got != tt.want {
+			t.Errorf("State(%q, %q) = %q, want %q", tt.timer, tt.phase, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+)
+
+// GoalChange is a daily goal in effect from a date (YYYY-MM-DD) onwards.
+// Weekdays holds per-weekday goals replacing Goal on those days; 0 makes the
+// weekday a day off. RestDays are off but keep their goal.
+type GoalChange struct {
+	From     string               `json:"from"`
+	Goal     int                  `json:"goal"`
+	Weekdays map[time.Weekday]int `json:"weekdays,omitempty"`
+	RestDays []time.Weekday       `json:"rest_days,omitempty"`
+}
+
+// RecordGoal notes that change is in effect from change.From on. It does
+// nothing when it already is, so it is safe to call on every config load;
+// past days keep the goals and rest days they had.
+func (s *Storage) RecordGoal(change GoalChange) error {
+	history, err := s.Goals()
+	if err != nil {
+		return err
+	}
+	restDays := slices.Clone(change.RestDays)
+	slices.Sort(restDays)
+	if len(history) > 0 {
+		current := goalChangeOn(history, change.From)
+		if current.Goal == change.Goal && maps.Equal(current.Weekdays, change.Weekdays) && slices.Equal(current.RestDays, restDays) {
+			return nil
+		}
+	}
+
+	var weekdays, rest string
+	if len(change.Weekdays) > 0 {
+		data, err := json.Marshal(change.Weekdays)
+		if err != nil {
+			return err
+		}
+		weekdays = string(data)
+	}
+	if len(restDays) > 0 {
+		data, err := json.Marshal(restDays)
+		if err != nil {
+			return err
+		}
+		rest = string(data)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO goals (effective_from, goal, weekday_goals, rest_days) VALUES (?, ?, ?, ?)
+		ON CONFLICT(effective_from) DO UPDATE SET
+			goal = excluded.goal, weekday_goals = excluded.weekday_goals, rest_days = excluded.rest_days`,
+		change.From, change.Goal, weekdays, rest,
+	)
+	return err
+}
+
+// Goals returns the goal history, oldest first.
+func (s *Storage) Goals() ([]GoalChange, error) {
+	rows, err := s.db.Query("SELECT effective_from, goal, weekday_goals, rest_days FROM goals ORDER BY effective_from")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []GoalChange
+	for rows.Next() {
+		var g GoalChange
+		var weekdays, rest string
+		if err := rows.Scan(&g.From, &g.Goal, &weekdays, &rest); err != nil {
+			return nil, err
+		}
+		if weekdays != "" {
+			if err := json.Unmarshal([]byte(weekdays), &g.Weekdays); err != nil {
+				return nil, err
+			}
+		}
+		if rest != "" {
+			if err := json.Unmarshal([]byte(rest), &g.RestDays); err != nil {
+				return nil, err
+			}
+		}
+		history = append(history, g)
+	}
+	return history, rows.Err()
+}
+
+// goalChangeOn returns the entry of a non-empty history in effect on date.
+// Days before the first entry use that first entry.
+func goalChangeOn(history []GoalChange, date string) GoalChange {
+	i := sort.Search(len(history), func(i int) bool { return history[i].From > date })
+	if i == 0 {
+		return history[0]
+	}
+	return history[i-1]
+}
+
+// GoalPlan decides the goal of any day from the goal history and
+// date-specific overrides.
+type GoalPlan struct {
+	History   []GoalChange
+	Current   GoalChange     // from Current.From on, or always with no History
+	Overrides map[string]int // by date; 0 is a day off
+}
+
+// DayGoal is the goal in effect on a day. Off days never break a streak and
+// don't count towards weekly hit rates; a rest day is off but keeps its goal
+// so meeting it still extends a streak.
+type DayGoal struct {
+	Goal int  `json:"goal"`
+	Off  bool `json:"off"`
+}
+
+// GoalPlan loads the goal history into a plan. current is the configured
+// goal, which applies from current.From (usually today) on, even before it
+// is recorded.
+func (s *Storage) GoalPlan(current GoalChange, overrides map[string]int) (GoalPlan, error) {
+	history, err := s.Goals()
+	return GoalPlan{History: history, Current: current, Overrides: overrides}, err
+}
+
+// Start is the first day the plan knows the goals of: the first recorded
+// change, or Current.From without a history.
+func (p GoalPlan) Start() string {
+	if len(p.History) > 0 && p.History[0].From < p.Current.From {
+		return p.History[0].From
+	}
+	return p.Current.From
+}
+
+// On returns the goal for date (YYYY-MM-DD). A date override wins over a
+// weekday goal, which wins over the daily goal.
+func (p GoalPlan) On(date string) DayGoal {
+	if goal, ok := p.Overrides[date]; ok {
+		return DayGoal{Goal: goal, Off: goal == 0}
+	}
+
+	change := p.Current
+	if len(p.History) > 0 && date < p.Current.From {
+		change = goalChangeOn(p.History, date)
+	}
+	t, err := time.ParseInLocation("2006-01-02", date, time.Local)
+	if err != nil {
+		return DayGoal{Goal: change.Goal}
+	}
+	if goal, ok := change.Weekdays[t.Weekday()]; ok {
+		return DayGoal{Goal: goal, Off: goal == 0}
+	}
+	return DayGoal{Goal: change.Goal, Off: slices.Contains(change.RestDays, t.Weekday())}
+}
+
+// Goals returns the goal of each day from the date from, for n days.
+func (p GoalPlan) Goals(from time.Time, n int) []int {
+	goals := make([]int, n)
+	for i := range goals {
+		goals[i] = p.On(from.AddDate(0, 0, i).Format("2006-01-02")).Goal
+	}
+	return goals
+}
@@ -0,0 +1,43 @@
+package storage
+
+import "fmt"
+
+// Heatmap counts work intervals by weekday and hour of day. Rows are ISO
+// weekdays, Monday first; columns are local hours 0 to 23.
+type Heatmap struct {
+	From         string     `json:"from"`
+	To           string     `json:"to"`
+	Counts       [7][24]int `json:"counts"`
+	FocusMinutes [7][24]int `json:"focus_minutes"`
+	Max          int        `json:"max"`
+}
+
+// Heatmap places the work intervals completed between two dates (YYYY-MM-DD,
+// inclusive; empty means unbounded) by the weekday and hour they started,
+// using StatsQuery's GroupWeekdayHour.
+func (s *Storage) Heatmap(from, to string) (Heatmap, error) {
+	h := Heatmap{From: from, To: to}
+	buckets, err := s.StatsQuery(StatsQuery{From: from, To: to, GroupBy: GroupWeekdayHour})
+	if err != nil {
+		return h, err
+	}
+	for _, b := range buckets {
+		var day, hour int
+		if _, err := fmt.Sscanf(b.Key, "%d-%d", &day, &hour); err != nil || day < 1 || day > 7 || hour < 0 || hour > 23 {
+			continue
+		}
+		h.Counts[day-1][hour] = b.Count
+		h.FocusMinutes[day-1][hour] = b.FocusMinutes
+		h.Max = max(h.Max, b.Count)
+	}
+	return h, nil
+}
+
+// Rows returns Counts as slices, for rendering.
+func (h Heatmap) Rows() [][]int {
+	rows := make([][]int, len(h.Counts))
+	for i := range h.Counts {
+		rows[i] = h.Counts[i][:]
+	}
+	return rows
+}
@@ -0,0 +1,59 @@
+package storage
+
+import "testing"
+
+func TestHeatmap(t *testing.T) {
+	s, _, _ := queryFixture(t)
+
+	h, err := s.Heatmap("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want [7][24]int
+	want[0][9], want[0][10] = 1, 1
+	want[2][9], want[2][14] = 1, 1 // the legacy row by when it completed
+	want[6][22] = 1
+	if h.Counts != want {
+		t.Errorf("counts = %v, want %v", h.Counts, want)
+	}
+	if h.FocusMinutes[0][10] != 50 || h.FocusMinutes[2][14] != 0 || h.Max != 1 {
+		t.Errorf("focus minutes Monday 10:00 %d, Wednesday 14:00 %d, max %d; want 50, 0, 1",
+			h.FocusMinutes[0][10], h.FocusMinutes[2][14], h.Max)
+	}
+
+	h, err = s.Heatmap("2026-03-02", "2026-03-04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.From != "2026-03-02" || h.To != "2026-03-04" {
+		t.Errorf("range %s..%s", h.From, h.To)
+	}
+	total := 0
+	for _, row := range h.Counts {
+		for _, n := range row {
+			total += n
+		}
+	}
+	if total != 3 || h.Counts[6][22] != 0 {
+		t.Errorf("%d intervals in range, Sunday 22:00 = %d; want 3 and 0", total, h.Counts[6][22])
+	}
+}
+
+// An interval is placed by the weekday it started on, even when it is
+// dated by the day it completed.
+func TestHeatmapAcrossMidnight(t *testing.T) {
+	s := openTest(t)
+	if _, err := s.db.Exec(
+		"INSERT INTO intervals (date, completed_at, started_at, duration_seconds) VALUES (?, ?, ?, ?)",
+		"2026-03-09", "2026-03-09T00:15:00+01:00", "2026-03-08T23:50:00+01:00", 25*60,
+	); err != nil {
+		t.Fatal(err)
+	}
+	h, err := s.Heatmap("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Counts[6][23] != 1 {
+		t.Errorf("Sunday 23:00 = %d, want the interval there; counts %v", h.Counts[6][23], h.Counts)
+	}
+}
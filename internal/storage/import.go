@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ImportResult summarizes an ImportSessions run.
+type ImportResult struct {
+	Imported     int `json:"imported"`
+	Duplicates   int `json:"duplicates"`
+	TasksCreated int `json:"tasks_created"`
+}
+
+// ImportSessions records sessions from another tool, or an earlier export,
+// in a single transaction. A session is a duplicate, and skipped, when an
+// interval already completed at the same instant. Tasks are matched by title
+// and project, and created as done when missing. With dryRun set nothing is
+// written but the result reports what would have been.
+//
+// Session, task and interruption IDs and dates are ignored; CompletedAt is
+// required and StartedAt may be empty.
+func (s *Storage) ImportSessions(sessions []Session, dryRun bool) (ImportResult, error) {
+	var result ImportResult
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	completions, err := completionTimes(tx)
+	if err != nil {
+		return result, err
+	}
+
+	tasks := make(map[[2]string]int64)
+	for i, sess := range sessions {
+		completed, err := time.Parse(time.RFC3339, sess.CompletedAt)
+		if err != nil {
+			return result, fmt.Errorf("session %d: invalid completed_at %q", i+1, sess.CompletedAt)
+		}
+		completed = completed.Local()
+
+		if completions[completed.Unix()] {
+			result.Duplicates++
+			continue
+		}
+		completions[completed.Unix()] = true
+
+		var startedAt sql.NullString
+		var duration sql.NullInt64
+		if sess.StartedAt != "" {
+			started, err := time.Parse(time.RFC3339, sess.StartedAt)
+			if err != nil {
+				return result, fmt.Errorf("session %d: invalid started_at %q", i+1, sess.StartedAt)
+			}
+			startedAt = sql.NullString{String: started.Local().Format(time.RFC3339), Valid: true}
+			if sess.DurationSeconds == 0 {
+				sess.DurationSeconds = int(completed.Sub(started).Seconds())
+			}
+		}
+		if sess.DurationSeconds > 0 {
+			duration = sql.NullInt64{Int64: int64(sess.DurationSeconds), Valid: true}
+		}
+
+		var taskID int64
+		if sess.Task != "" {
+			key := [2]string{sess.Task, sess.Project}
+			id, ok := tasks[key]
+			if !ok {
+				var created bool
+				id, created, err = importTask(tx, sess.Task, sess.Project, completed)
+				if err != nil {
+					return result, err
+				}
+				if created {
+					result.TasksCreated++
+				}
+				tasks[key] = id
+			}
+			taskID = id
+		}
+
+		res, err := tx.Exec(
+			"INSERT INTO intervals (date, completed_at, task_id, note, started_at, duration_seconds) VALUES (?, ?, ?, ?, ?, ?)",
+			completed.Format("2006-01-02"), completed.Format(time.RFC3339), nullID(taskID), sess.Note, startedAt, duration,
+		)
+		if err != nil {
+			return result, err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return result, err
+		}
+
+		for _, tag := range sess.Tags {
+			if tag = NormalizeTag(tag); tag == "" {
+				continue
+			}
+			if _, err := tx.Exec("INSERT OR IGNORE INTO interval_tags (interval_id, tag) VALUES (?, ?)", id, tag); err != nil {
+				return result, err
+			}
+		}
+
+		interruptions, err := importInterruptions(sess, completed, startedAt)
+		if err != nil {
+			return result, fmt.Errorf("session %d: %w", i+1, err)
+		}
+		for _, x := range interruptions {
+			occurred, _ := time.Parse(time.RFC3339, x.OccurredAt)
+			if _, err := tx.Exec(
+				"INSERT INTO interruptions (interval_id, kind, note, date, occurred_at) VALUES (?, ?, ?, ?, ?)",
+				id, x.Kind, x.Note, occurred.Format("2006-01-02"), x.OccurredAt,
+			); err != nil {
+				return result, err
+			}
+		}
+
+		result.Imported++
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	return result, tx.Commit()
+}
+
+// completionTimes is the set of instants, in Unix seconds, at which recorded
+// intervals completed. Stored times carry the offset of their moment, so they
+// are compared as instants rather than as strings, and loading them once
+// saves a scan of the table for every imported session.
+func completionTimes(tx *sql.Tx) (map[int64]bool, error) {
+	rows, err := tx.Query("SELECT completed_at FROM intervals")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := make(map[int64]bool)
+	for rows.Next() {
+		var completedAt string
+		if err := rows.Scan(&completedAt); err != nil {
+			return nil, err
+		}
+		if t, err := time.Parse(time.RFC3339, completedAt); err == nil {
+			times[t.Unix()] = true
+		}
+	}
+	return times, rows.Err()
+}
+
+// importInterruptions are the interruptions to record for sess: its listed
+// ones, as exported in JSON, or else as many as it counts. Counted ones have
+// no time of their own; they are placed at the start of the session, or its
+// end when that is unknown.
+func importInterruptions(sess Session, completed time.Time, startedAt sql.NullString) ([]Interruption, error) {
+	if len(sess.Interruptions) > 0 {
+		list := make([]Interruption, len(sess.Interruptions))
+		for i, x := range sess.Interruptions {
+			if x.Kind != InterruptionInternal && x.Kind != InterruptionExternal {
+				return nil, fmt.Errorf("invalid interruption kind %q", x.Kind)
+			}
+			occurred, err := time.Parse(time.RFC3339, x.OccurredAt)
+			if err != nil {
+				return nil, fmt.Errorf("invalid interruption occurred_at %q", x.OccurredAt)
+			}
+			list[i] = Interruption{Kind: x.Kind, Note: x.Note, OccurredAt: occurred.Local().Format(time.RFC3339)}
+		}
+		return list, nil
+	}
+
+	occurred := completed.Format(time.RFC3339)
+	if startedAt.Valid {
+		occurred = startedAt.String
+	}
+	var list []Interruption
+	for range sess.InterruptionsInternal {
+		list = append(list, Interruption{Kind: InterruptionInternal, OccurredAt: occurred})
+	}
+	for range sess.InterruptionsExternal {
+		list = append(list, Interruption{Kind: InterruptionExternal, OccurredAt: occurred})
+	}
+	return list, nil
+}
+
+// importTask returns the task with title and project, creating it as done
+// at doneAt when there is none.
+func importTask(tx *sql.Tx, title, project string, doneAt time.Time) (int64, bool, error) {
+	var id int64
+	err := tx.QueryRow(
+		"SELECT id FROM tasks WHERE title = ? AND project = ? ORDER BY id LIMIT 1",
+		title, project,
+	).Scan(&id)
+	if err == nil {
+		return id, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, err
+	}
+
+	at := doneAt.Format(time.RFC3339)
+	res, err := tx.Exec(
+		"INSERT INTO tasks (title, project, status, created_at, done_at) VALUES (?, ?, ?, ?, ?)",
+		title, project, TaskDone, at, at,
+	)
+	if err != nil {
+		return 0, false, err
+	}
+	id, err = res.LastInsertId()
+	return id, true, err
+}
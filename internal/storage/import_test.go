@@ -0,0 +1,76 @@
+package storage
+
+import "testing"
+
+func TestImportSessionsDuplicates(t *testing.T) {
+	s := openTest(t)
+	addInterval(t, s, "2026-03-02T09:00:00+01:00", 25, 0) // completes 09:25+01:00
+
+	result, err := s.ImportSessions([]Session{
+		// The recorded interval, written with another offset.
+		{CompletedAt: "2026-03-02T08:25:00Z"},
+		{StartedAt: "2026-03-02T10:00:00Z", CompletedAt: "2026-03-02T10:25:00Z"},
+		// Repeated within the import.
+		{CompletedAt: "2026-03-02T12:25:00+02:00"},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 1 || result.Duplicates != 2 {
+		t.Errorf("result = %+v, want 1 imported and 2 duplicates", result)
+	}
+
+	// Running it again imports nothing.
+	result, err = s.ImportSessions([]Session{{CompletedAt: "2026-03-02T10:25:00Z"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 0 || result.Duplicates != 1 {
+		t.Errorf("second run = %+v, want only a duplicate", result)
+	}
+}
+
+func TestImportSessionsInterruptions(t *testing.T) {
+	s := openTest(t)
+	_, err := s.ImportSessions([]Session{{
+		StartedAt:   "2026-03-02T09:00:00Z",
+		CompletedAt: "2026-03-02T09:25:00Z",
+		// Listed interruptions win over the counts.
+		InterruptionsInternal: 5,
+		Interruptions: []Interruption{
+			{Kind: InterruptionExternal, Note: "phone", OccurredAt: "2026-03-02T09:10:00Z"},
+		},
+	}, {
+		StartedAt:             "2026-03-02T10:00:00Z",
+		CompletedAt:           "2026-03-02T10:25:00Z",
+		InterruptionsInternal: 2,
+	}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []Session
+	if err := s.Sessions("", "", func(sess Session) error {
+		got = append(got, sess)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d sessions", len(got))
+	}
+	if list := got[0].Interruptions; len(list) != 1 || list[0].Note != "phone" || got[0].InterruptionsExternal != 1 {
+		t.Errorf("first session = %+v", got[0])
+	}
+	if list := got[1].Interruptions; len(list) != 2 || list[0].Kind != InterruptionInternal || got[1].InterruptionsInternal != 2 {
+		t.Errorf("second session = %+v", got[1])
+	}
+
+	_, err = s.ImportSessions([]Session{{
+		CompletedAt:   "2026-03-03T09:25:00Z",
+		Interruptions: []Interruption{{Kind: "phone", OccurredAt: "2026-03-03T09:10:00Z"}},
+	}}, false)
+	if err == nil {
+		t.Error("imported an interruption of an unknown kind")
+	}
+}
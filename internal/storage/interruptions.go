@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	InterruptionInternal = "internal"
+	InterruptionExternal = "external"
+)
+
+type Interruption struct {
+	ID         int64  `json:"id"`
+	IntervalID int64  `json:"interval_id,omitempty"`
+	Kind       string `json:"kind"`
+	Note       string `json:"note,omitempty"`
+	OccurredAt string `json:"occurred_at"`
+}
+
+type InterruptionStats struct {
+	Internal  int `json:"internal"`
+	External  int `json:"external"`
+	Intervals int `json:"intervals"`
+}
+
+func (s InterruptionStats) Total() int {
+	return s.Internal + s.External
+}
+
+// PerInterval is the average number of interruptions per completed work
+// interval.
+func (s InterruptionStats) PerInterval() float64 {
+	if s.Intervals == 0 {
+		return 0
+	}
+	return float64(s.Total()) / float64(s.Intervals)
+}
+
+// RecordInterruption logs an interruption of the running work interval. It
+// is linked to the interval once that completes.
+func (s *Storage) RecordInterruption(kind, note string) (Interruption, error) {
+	if kind != InterruptionInternal && kind != InterruptionExternal {
+		return Interruption{}, fmt.Errorf("interruption kind must be %q or %q", InterruptionInternal, InterruptionExternal)
+	}
+
+	now := time.Now()
+	i := Interruption{Kind: kind, Note: note, OccurredAt: now.Format(time.RFC3339)}
+	res, err := s.db.Exec(
+		"INSERT INTO interruptions (kind, note, date, occurred_at) VALUES (?, ?, ?, ?)",
+		kind, note, now.Format("2006-01-02"), i.OccurredAt,
+	)
+	if err != nil {
+		return Interruption{}, err
+	}
+	i.ID, err = res.LastInsertId()
+	return i, err
+}
+
+// PendingInterruptions returns interruptions logged since the given time that
+// are not yet linked to a completed interval. Like RecordInterval, it compares
+// instants rather than the stored strings, whose offsets may differ.
+func (s *Storage) PendingInterruptions(since time.Time) ([]Interruption, error) {
+	rows, err := s.db.Query(
+		`SELECT id, kind, note, occurred_at FROM interruptions
+		WHERE interval_id IS NULL AND julianday(occurred_at) >= julianday(?)
+		ORDER BY julianday(occurred_at), id`,
+		since.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Interruption
+	for rows.Next() {
+		var i Interruption
+		if err := rows.Scan(&i.ID, &i.Kind, &i.Note, &i.OccurredAt); err != nil {
+			return nil, err
+		}
+		list = append(list, i)
+	}
+	return list, rows.Err()
+}
+
+// InterruptionCounts totals interruptions and completed work intervals
+// between two dates (YYYY-MM-DD, inclusive).
+func (s *Storage) InterruptionCounts(from, to string) (InterruptionStats, error) {
+	var st InterruptionStats
+	err := s.db.QueryRow(`
+		SELECT
+			COALESCE(SUM(kind = 'internal'), 0),
+			COALESCE(SUM(kind = 'external'), 0)
+		FROM interruptions WHERE date BETWEEN ? AND ?`,
+		from, to,
+	).Scan(&st.Internal, &st.External)
+	if err != nil {
+		return st, err
+	}
+	err = s.db.QueryRow(
+		"SELECT COUNT(*) FROM intervals WHERE date BETWEEN ? AND ?",
+		from, to,
+	).Scan(&st.Intervals)
+	return st, err
+}
+
+// InterruptionMarks renders interruptions in classic Pomodoro notation:
+// ' for internal and - for external.
+func InterruptionMarks(list []Interruption) string {
+	marks := make([]byte, 0, len(list))
+	for _, i := range list {
+		if i.Kind == InterruptionInternal {
+			marks = append(marks, '\'')
+		} else {
+			marks = append(marks, '-')
+		}
+	}
+	return string(marks)
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func addInterruption(t *testing.T, s *Storage, kind, occurredAt string) int64 {
+	t.Helper()
+	res, err := s.db.Exec(
+		"INSERT INTO interruptions (kind, note, date, occurred_at) VALUES (?, '', ?, ?)",
+		kind, occurredAt[:10], occurredAt,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := res.LastInsertId()
+	return id
+}
+
+// Around the end of daylight saving time the same wall clock comes twice
+// with different offsets, and the stored strings no longer sort by time.
+func TestInterruptionsAcrossOffsetChange(t *testing.T) {
+	s := openTest(t)
+	cest := time.FixedZone("CEST", 2*60*60)
+	started := time.Date(2026, 10, 25, 2, 50, 0, 0, cest) // 00:50 UTC
+
+	before := addInterruption(t, s, InterruptionInternal, "2026-10-25T02:30:00+02:00") // 00:30 UTC
+	after := addInterruption(t, s, InterruptionExternal, "2026-10-25T02:05:00+01:00")  // 01:05 UTC
+
+	pending, err := s.PendingInterruptions(started)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].ID != after {
+		t.Errorf("pending = %+v, want only interruption %d", pending, after)
+	}
+
+	id, err := s.RecordInterval(Interval{StartedAt: started, Duration: 25 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked := func(interruption int64) bool {
+		var intervalID *int64
+		if err := s.db.QueryRow("SELECT interval_id FROM interruptions WHERE id = ?", interruption).Scan(&intervalID); err != nil {
+			t.Fatal(err)
+		}
+		return intervalID != nil && *intervalID == id
+	}
+	if linked(before) {
+		t.Error("interruption before the interval started was linked to it")
+	}
+	if !linked(after) {
+		t.Error("interruption during the interval was not linked to it")
+	}
+}
+
+func TestPendingInterruptionsOrder(t *testing.T) {
+	s := openTest(t)
+	since := time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC)
+	second := addInterruption(t, s, InterruptionInternal, "2026-10-25T02:40:00+02:00") // 00:40 UTC
+	first := addInterruption(t, s, InterruptionInternal, "2026-10-25T00:20:00Z")
+
+	pending, err := s.PendingInterruptions(since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].ID != first || pending[1].ID != second {
+		t.Errorf("pending = %+v, want %d then %d", pending, first, second)
+	}
+	if marks := InterruptionMarks(pending); marks != "''" {
+		t.Errorf("marks = %q", marks)
+	}
+}
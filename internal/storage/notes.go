@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrNoInterval = errors.New("no completed work interval")
+
+var hashtagPattern = regexp.MustCompile(`(?:^|\s)#([\pL\pN_][\pL\pN_-]*)`)
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+type SessionNote struct {
+	IntervalID  int64    `json:"interval_id"`
+	CompletedAt string   `json:"completed_at"`
+	Note        string   `json:"note"`
+	Tags        []string `json:"tags"`
+}
+
+// NormalizeTag lower-cases a tag and strips a leading '#'.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// ParseTags returns the #hashtags found in a note, normalized.
+func ParseTags(note string) []string {
+	var tags []string
+	for _, m := range hashtagPattern.FindAllStringSubmatch(note, -1) {
+		tags = append(tags, NormalizeTag(m[1]))
+	}
+	return tags
+}
+
+// LastIntervalID returns the most recently completed work interval.
+func (s *Storage) LastIntervalID() (int64, error) {
+	var id int64
+	err := s.db.QueryRow("SELECT id FROM intervals ORDER BY completed_at DESC, id DESC LIMIT 1").Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNoInterval
+	}
+	return id, err
+}
+
+// AnnotateInterval appends note to an interval's note, if non-empty, and
+// adds tags plus any #hashtags found in the note.
+func (s *Storage) AnnotateInterval(id int64, note string, tags []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if note != "" {
+		res, err := tx.Exec(`
+			UPDATE intervals
+			SET note = CASE WHEN note = '' THEN ?1 ELSE note || '; ' || ?1 END
+			WHERE id = ?2`,
+			note, id,
+		)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNoInterval
+		}
+	}
+
+	for _, tag := range append(tags, ParseTags(note)...) {
+		tag = NormalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO interval_tags (interval_id, tag) VALUES (?, ?)",
+			id, tag,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// TagCounts counts tagged intervals between two dates (YYYY-MM-DD,
+// inclusive), most used first.
+func (s *Storage) TagCounts(from, to string) ([]TagCount, error) {
+	rows, err := s.db.Query(`
+		SELECT t.tag, COUNT(*) FROM interval_tags t
+		JOIN intervals i ON i.id = t.interval_id
+		WHERE i.date BETWEEN ? AND ?
+		GROUP BY t.tag
+		ORDER BY COUNT(*) DESC, t.tag`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []TagCount
+	for rows.Next() {
+		var c TagCount
+		if err := rows.Scan(&c.Tag, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+// Notes returns intervals between two dates (inclusive) that have a note or
+// tags, in completion order.
+func (s *Storage) Notes(from, to string) ([]SessionNote, error) {
+	rows, err := s.db.Query(`
+		SELECT i.id, i.completed_at, i.note, COALESCE(GROUP_CONCAT(t.tag, ','), '')
+		FROM intervals i
+		LEFT JOIN interval_tags t ON t.interval_id = i.id
+		WHERE i.date BETWEEN ? AND ?
+		GROUP BY i.id
+		HAVING i.note != '' OR COUNT(t.tag) > 0
+		ORDER BY i.completed_at, i.id`,
+		from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notes []SessionNote
+	for rows.Next() {
+		var n SessionNote
+		var tags string
+		if err := rows.Scan(&n.IntervalID, &n.CompletedAt, &n.Note, &tags); err != nil {
+			return nil, err
+		}
+		n.Tags = splitTags(tags)
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+func splitTags(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
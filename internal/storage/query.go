@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GroupBy selects how StatsQuery buckets work intervals.
+type GroupBy string
+
+const (
+	GroupDay     GroupBy = "day"     // key 2026-03-02
+	GroupWeek    GroupBy = "week"    // ISO week, key 2026-W10
+	GroupMonth   GroupBy = "month"   // key 2026-03
+	GroupWeekday GroupBy = "weekday" // ISO weekday the interval started, key 1 (Monday) to 7
+	GroupHour    GroupBy = "hour"    // local hour the interval started, key 00 to 23
+	GroupTask    GroupBy = "task"    // key task id, "" for none
+	GroupTag     GroupBy = "tag"     // key tag, "" for untagged; tags overlap
+
+	// GroupWeekdayHour is the ISO weekday and local hour the interval
+	// started, key 1-00 (Monday, midnight) to 7-23.
+	GroupWeekdayHour GroupBy = "weekday-hour"
+)
+
+var groupings = []GroupBy{GroupDay, GroupWeek, GroupMonth, GroupWeekday, GroupHour, GroupWeekdayHour, GroupTask, GroupTag}
+
+// startedAt is when an interval started; intervals recorded before start
+// times were tracked use their completion time.
+const startedAt = "COALESCE(i.started_at, i.completed_at)"
+
+func ParseGroupBy(s string) (GroupBy, error) {
+	for _, g := range groupings {
+		if string(g) == strings.ToLower(s) {
+			return g, nil
+		}
+	}
+	names := make([]string, len(groupings))
+	for i, g := range groupings {
+		names[i] = string(g)
+	}
+	return "", fmt.Errorf("unknown grouping %q (use %s)", s, strings.Join(names, ", "))
+}
+
+// StatsQuery selects work intervals completed between two dates and how to
+// group them.
+type StatsQuery struct {
+	From, To string // YYYY-MM-DD, inclusive; empty means unbounded
+	GroupBy  GroupBy
+	Tag      string // only intervals with this tag
+	TaskID   int64  // only intervals linked to this task
+
+	// Fill adds empty buckets so the result has no gaps: every day, week or
+	// month in the range (which then needs From and To), every weekday,
+	// every hour or every hour of every weekday.
+	Fill bool
+}
+
+// Bucket is one group of a StatsQuery result. FocusMinutes only counts
+// intervals whose duration is known.
+type Bucket struct {
+	Key          string `json:"key"`
+	Label        string `json:"label"`
+	Count        int    `json:"count"`
+	FocusMinutes int    `json:"focus_minutes"`
+}
+
+// groupKeys are the SQL expressions for each grouping's key and label. The
+// week key uses the Thursday of the interval's week, which decides its ISO
+// year and week number.
+var groupKeys = map[GroupBy]struct{ key, label, join string }{
+	GroupDay:   {key: "i.date", label: "i.date"},
+	GroupMonth: {key: "substr(i.date, 1, 7)", label: "substr(i.date, 1, 7)"},
+	GroupWeek: {
+		key: `strftime('%Y', date(i.date, '-3 days', 'weekday 4')) || '-W' ||
+			printf('%02d', (strftime('%j', date(i.date, '-3 days', 'weekday 4')) - 1) / 7 + 1)`,
+	},
+	GroupWeekday: {key: "CAST((strftime('%w', substr(" + startedAt + ", 1, 10)) + 6) % 7 + 1 AS TEXT)"},
+	GroupHour:    {key: "substr(" + startedAt + ", 12, 2)"},
+	GroupWeekdayHour: {
+		key: "CAST((strftime('%w', substr(" + startedAt + ", 1, 10)) + 6) % 7 + 1 AS TEXT) || '-' || substr(" + startedAt + ", 12, 2)",
+	},
+	GroupTask: {
+		key:   "COALESCE(CAST(i.task_id AS TEXT), '')",
+		label: "COALESCE(tk.title, '')",
+		join:  "LEFT JOIN tasks tk ON tk.id = i.task_id",
+	},
+	GroupTag: {
+		key:  "COALESCE(g.tag, '')",
+		join: "LEFT JOIN interval_tags g ON g.interval_id = i.id",
+	},
+}
+
+// StatsQuery counts work intervals and their focused minutes per group in a
+// single grouped query. Buckets are ordered by key.
+func (s *Storage) StatsQuery(q StatsQuery) ([]Bucket, error) {
+	g, ok := groupKeys[q.GroupBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown grouping %q", q.GroupBy)
+	}
+	label := g.label
+	if label == "" {
+		label = "''"
+	}
+
+	from, to := q.From, q.To
+	if from == "" {
+		from = "0000-00-00"
+	}
+	if to == "" {
+		to = "9999-99-99"
+	}
+
+	where := []string{"i.date BETWEEN ? AND ?"}
+	args := []any{from, to}
+	if q.Tag != "" {
+		where = append(where, "EXISTS (SELECT 1 FROM interval_tags f WHERE f.interval_id = i.id AND f.tag = ?)")
+		args = append(args, NormalizeTag(q.Tag))
+	}
+	if q.TaskID != 0 {
+		where = append(where, "i.task_id = ?")
+		args = append(args, q.TaskID)
+	}
+
+	rows, err := s.db.Query(fmt.Sprintf(`
+		SELECT %s AS k, MAX(%s), COUNT(*), COALESCE(SUM(i.duration_seconds), 0)
+		FROM intervals i %s
+		WHERE %s
+		GROUP BY k
+		ORDER BY k`,
+		g.key, label, g.join, strings.Join(where, " AND ")),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []Bucket
+	for rows.Next() {
+		var b Bucket
+		var seconds int
+		if err := rows.Scan(&b.Key, &b.Label, &b.Count, &seconds); err != nil {
+			return nil, err
+		}
+		b.FocusMinutes = (seconds + 30) / 60
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if q.Fill {
+		buckets = fill(q, buckets)
+	}
+	for i := range buckets {
+		buckets[i].Label = bucketLabel(q.GroupBy, buckets[i])
+	}
+	if q.GroupBy == GroupTask {
+		// Task ids sort as text; order them numerically, no task first.
+		sort.SliceStable(buckets, func(i, j int) bool {
+			a, _ := strconv.ParseInt(buckets[i].Key, 10, 64)
+			b, _ := strconv.ParseInt(buckets[j].Key, 10, 64)
+			return a < b
+		})
+	}
+	return buckets, nil
+}
+
+func bucketLabel(g GroupBy, b Bucket) string {
+	switch g {
+	case GroupWeekday:
+		n, _ := strconv.Atoi(b.Key)
+		return time.Weekday(n % 7).String()
+	case GroupHour:
+		return b.Key + ":00"
+	case GroupWeekdayHour:
+		day, hour, _ := strings.Cut(b.Key, "-")
+		n, _ := strconv.Atoi(day)
+		return time.Weekday(n%7).String() + " " + hour + ":00"
+	case GroupTask:
+		if b.Key == "" {
+			return "(no task)"
+		}
+	case GroupTag:
+		if b.Key == "" {
+			return "(untagged)"
+		}
+	}
+	if b.Label == "" {
+		return b.Key
+	}
+	return b.Label
+}
+
+// fill inserts empty buckets for keys missing from buckets.
+func fill(q StatsQuery, buckets []Bucket) []Bucket {
+	var keys []string
+	switch q.GroupBy {
+	case GroupWeekday:
+		for n := 1; n <= 7; n++ {
+			keys = append(keys, strconv.Itoa(n))
+		}
+	case GroupHour:
+		for h := 0; h < 24; h++ {
+			keys = append(keys, fmt.Sprintf("%02d", h))
+		}
+	case GroupWeekdayHour:
+		for n := 1; n <= 7; n++ {
+			for h := 0; h < 24; h++ {
+				keys = append(keys, fmt.Sprintf("%d-%02d", n, h))
+			}
+		}
+	case GroupDay, GroupWeek, GroupMonth:
+		from, err1 := time.ParseInLocation("2006-01-02", q.From, time.Local)
+		to, err2 := time.ParseInLocation("2006-01-02", q.To, time.Local)
+		if err1 != nil || err2 != nil {
+			return buckets
+		}
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			key := dateKey(q.GroupBy, d)
+			if len(keys) == 0 || keys[len(keys)-1] != key {
+				keys = append(keys, key)
+			}
+		}
+	default:
+		return buckets
+	}
+
+	have := make(map[string]Bucket, len(buckets))
+	for _, b := range buckets {
+		have[b.Key] = b
+	}
+	filled := make([]Bucket, len(keys))
+	for i, key := range keys {
+		if b, ok := have[key]; ok {
+			filled[i] = b
+		} else {
+			filled[i] = Bucket{Key: key}
+		}
+	}
+	return filled
+}
+
+// dateKey is the Go equivalent of the SQL keys for date groupings.
+func dateKey(g GroupBy, d time.Time) string {
+	switch g {
+	case GroupWeek:
+		year, week := d.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case GroupMonth:
+		return d.Format("2006-01")
+	}
+	return d.Format("2006-01-02")
+}
@@ -0,0 +1,361 @@
+package storage
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func openTest(t *testing.T) *Storage {
+	t.Helper()
+	s, err := Open(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// addInterval stores a work interval started at start, an RFC3339 time,
+// lasting minutes. A zero minutes stores a legacy row with only completed_at.
+func addInterval(t *testing.T, s *Storage, start string, minutes int, taskID int64, tags ...string) {
+	t.Helper()
+	started, err := time.Parse(time.RFC3339, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var startedAt, duration, task any
+	if minutes > 0 {
+		startedAt, duration = start, minutes*60
+	}
+	if taskID != 0 {
+		task = taskID
+	}
+	completed := started.Add(time.Duration(minutes) * time.Minute).Format(time.RFC3339)
+	res, err := s.db.Exec(
+		"INSERT INTO intervals (date, completed_at, started_at, duration_seconds, task_id) VALUES (?, ?, ?, ?, ?)",
+		start[:10], completed, startedAt, duration, task,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := res.LastInsertId()
+	for _, tag := range tags {
+		if _, err := s.db.Exec("INSERT INTO interval_tags (interval_id, tag) VALUES (?, ?)", id, tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// queryFixture has two tasks and five intervals over a week boundary and two
+// months, one of them a legacy row without a start or duration.
+func queryFixture(t *testing.T) (*Storage, Task, Task) {
+	s := openTest(t)
+	a, err := s.AddTask("Write", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.AddTask("Review", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addInterval(t, s, "2026-03-01T22:00:00+01:00", 25, a.ID, "work")           // Sunday, 2026-W09
+	addInterval(t, s, "2026-03-02T09:00:00+01:00", 25, a.ID, "work", "review") // Monday, 2026-W10
+	addInterval(t, s, "2026-03-02T10:00:00+01:00", 50, 0)
+	addInterval(t, s, "2026-03-04T09:00:00+01:00", 25, b.ID, "review")
+	addInterval(t, s, "2026-04-01T14:30:00+02:00", 0, 0)
+	return s, a, b
+}
+
+func TestStatsQueryGroupBy(t *testing.T) {
+	s, a, b := queryFixture(t)
+	taskKey := func(task Task) string { return strconv.FormatInt(task.ID, 10) }
+
+	tests := []struct {
+		group GroupBy
+		want  []Bucket
+	}{
+		{GroupDay, []Bucket{
+			{Key: "2026-03-01", Label: "2026-03-01", Count: 1, FocusMinutes: 25},
+			{Key: "2026-03-02", Label: "2026-03-02", Count: 2, FocusMinutes: 75},
+			{Key: "2026-03-04", Label: "2026-03-04", Count: 1, FocusMinutes: 25},
+			{Key: "2026-04-01", Label: "2026-04-01", Count: 1},
+		}},
+		{GroupWeek, []Bucket{
+			{Key: "2026-W09", Label: "2026-W09", Count: 1, FocusMinutes: 25},
+			{Key: "2026-W10", Label: "2026-W10", Count: 3, FocusMinutes: 100},
+			{Key: "2026-W14", Label: "2026-W14", Count: 1},
+		}},
+		{GroupMonth, []Bucket{
+			{Key: "2026-03", Label: "2026-03", Count: 4, FocusMinutes: 125},
+			{Key: "2026-04", Label: "2026-04", Count: 1},
+		}},
+		{GroupWeekday, []Bucket{
+			{Key: "1", Label: "Monday", Count: 2, FocusMinutes: 75},
+			{Key: "3", Label: "Wednesday", Count: 2, FocusMinutes: 25},
+			{Key: "7", Label: "Sunday", Count: 1, FocusMinutes: 25},
+		}},
+		{GroupHour, []Bucket{
+			{Key: "09", Label: "09:00", Count: 2, FocusMinutes: 50},
+			{Key: "10", Label: "10:00", Count: 1, FocusMinutes: 50},
+			// The legacy row falls back to when it completed.
+			{Key: "14", Label: "14:00", Count: 1},
+			{Key: "22", Label: "22:00", Count: 1, FocusMinutes: 25},
+		}},
+		{GroupWeekdayHour, []Bucket{
+			{Key: "1-09", Label: "Monday 09:00", Count: 1, FocusMinutes: 25},
+			{Key: "1-10", Label: "Monday 10:00", Count: 1, FocusMinutes: 50},
+			{Key: "3-09", Label: "Wednesday 09:00", Count: 1, FocusMinutes: 25},
+			{Key: "3-14", Label: "Wednesday 14:00", Count: 1},
+			{Key: "7-22", Label: "Sunday 22:00", Count: 1, FocusMinutes: 25},
+		}},
+		{GroupTask, []Bucket{
+			{Key: "", Label: "(no task)", Count: 2, FocusMinutes: 50},
+			{Key: taskKey(a), Label: "Write", Count: 2, FocusMinutes: 50},
+			{Key: taskKey(b), Label: "Review", Count: 1, FocusMinutes: 25},
+		}},
+		// An interval with two tags counts under both.
+		{GroupTag, []Bucket{
+			{Key: "", Label: "(untagged)", Count: 2, FocusMinutes: 50},
+			{Key: "review", Label: "review", Count: 2, FocusMinutes: 50},
+			{Key: "work", Label: "work", Count: 2, FocusMinutes: 50},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.group), func(t *testing.T) {
+			got, err := s.StatsQuery(StatsQuery{GroupBy: tt.group})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsQueryRange(t *testing.T) {
+	s, _, _ := queryFixture(t)
+	got, err := s.StatsQuery(StatsQuery{From: "2026-03-02", To: "2026-03-31", GroupBy: GroupMonth})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Bucket{{Key: "2026-03", Label: "2026-03", Count: 3, FocusMinutes: 100}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestStatsQueryFilters(t *testing.T) {
+	s, a, _ := queryFixture(t)
+
+	tests := []struct {
+		name string
+		q    StatsQuery
+		want []Bucket
+	}{
+		{"tag", StatsQuery{GroupBy: GroupDay, Tag: "review"}, []Bucket{
+			{Key: "2026-03-02", Label: "2026-03-02", Count: 1, FocusMinutes: 25},
+			{Key: "2026-03-04", Label: "2026-03-04", Count: 1, FocusMinutes: 25},
+		}},
+		{"tag is normalized", StatsQuery{GroupBy: GroupMonth, Tag: "#Work"}, []Bucket{
+			{Key: "2026-03", Label: "2026-03", Count: 2, FocusMinutes: 50},
+		}},
+		{"task", StatsQuery{GroupBy: GroupDay, TaskID: a.ID}, []Bucket{
+			{Key: "2026-03-01", Label: "2026-03-01", Count: 1, FocusMinutes: 25},
+			{Key: "2026-03-02", Label: "2026-03-02", Count: 1, FocusMinutes: 25},
+		}},
+		{"tag and task", StatsQuery{GroupBy: GroupDay, Tag: "review", TaskID: a.ID}, []Bucket{
+			{Key: "2026-03-02", Label: "2026-03-02", Count: 1, FocusMinutes: 25},
+		}},
+		// The other tags of a matching interval still group it.
+		{"tag grouped by tag", StatsQuery{GroupBy: GroupTag, Tag: "review"}, []Bucket{
+			{Key: "review", Label: "review", Count: 2, FocusMinutes: 50},
+			{Key: "work", Label: "work", Count: 1, FocusMinutes: 25},
+		}},
+		{"no match", StatsQuery{GroupBy: GroupDay, Tag: "none"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.StatsQuery(tt.q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsQueryFill(t *testing.T) {
+	s, _, _ := queryFixture(t)
+
+	keys := func(buckets []Bucket) []string {
+		var keys []string
+		for _, b := range buckets {
+			keys = append(keys, b.Key)
+		}
+		return keys
+	}
+	counts := func(buckets []Bucket) []int {
+		var counts []int
+		for _, b := range buckets {
+			counts = append(counts, b.Count)
+		}
+		return counts
+	}
+
+	days, err := s.StatsQuery(StatsQuery{From: "2026-02-28", To: "2026-03-05", GroupBy: GroupDay, Fill: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2026-02-28", "2026-03-01", "2026-03-02", "2026-03-03", "2026-03-04", "2026-03-05"}; !reflect.DeepEqual(keys(days), want) {
+		t.Errorf("day keys = %v, want %v", keys(days), want)
+	}
+	if want := []int{0, 1, 2, 0, 1, 0}; !reflect.DeepEqual(counts(days), want) {
+		t.Errorf("day counts = %v, want %v", counts(days), want)
+	}
+	if days[0].Label != "2026-02-28" {
+		t.Errorf("empty day label = %q, want the date", days[0].Label)
+	}
+
+	weeks, err := s.StatsQuery(StatsQuery{From: "2026-02-23", To: "2026-04-05", GroupBy: GroupWeek, Fill: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"2026-W09", "2026-W10", "2026-W11", "2026-W12", "2026-W13", "2026-W14"}; !reflect.DeepEqual(keys(weeks), want) {
+		t.Errorf("week keys = %v, want %v", keys(weeks), want)
+	}
+	if want := []int{1, 3, 0, 0, 0, 1}; !reflect.DeepEqual(counts(weeks), want) {
+		t.Errorf("week counts = %v, want %v", counts(weeks), want)
+	}
+
+	months, err := s.StatsQuery(StatsQuery{From: "2026-02-15", To: "2026-04-15", GroupBy: GroupMonth, Fill: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{0, 4, 1}; !reflect.DeepEqual(counts(months), want) {
+		t.Errorf("month counts = %v, want %v", counts(months), want)
+	}
+
+	weekdays, err := s.StatsQuery(StatsQuery{GroupBy: GroupWeekday, Fill: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{2, 0, 2, 0, 0, 0, 1}; !reflect.DeepEqual(counts(weekdays), want) {
+		t.Errorf("weekday counts = %v, want %v", counts(weekdays), want)
+	}
+	if weekdays[1].Label != "Tuesday" {
+		t.Errorf("empty weekday label = %q, want Tuesday", weekdays[1].Label)
+	}
+
+	hours, err := s.StatsQuery(StatsQuery{GroupBy: GroupHour, Fill: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hours) != 24 || hours[0].Key != "00" || hours[23].Key != "23" {
+		t.Fatalf("hour keys = %v, want 00 to 23", keys(hours))
+	}
+	if hours[9].Count != 2 || hours[22].Count != 1 || hours[0].Label != "00:00" {
+		t.Errorf("hours = %+v", hours)
+	}
+
+	cells, err := s.StatsQuery(StatsQuery{GroupBy: GroupWeekdayHour, Fill: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cells) != 7*24 || cells[0].Key != "1-00" || cells[167].Key != "7-23" {
+		t.Fatalf("weekday-hour fill gave %d buckets from %s", len(cells), cells[0].Key)
+	}
+	if cells[9].Count != 1 || cells[6*24+22].Count != 1 || cells[6*24+22].Label != "Sunday 22:00" {
+		t.Errorf("Monday 09:00 = %+v, Sunday 22:00 = %+v", cells[9], cells[6*24+22])
+	}
+
+	// Without both ends there is no range to fill.
+	open, err := s.StatsQuery(StatsQuery{From: "2026-03-01", GroupBy: GroupDay, Fill: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 4 {
+		t.Errorf("open-ended fill gave %d buckets, want the 4 with data", len(open))
+	}
+}
+
+func TestStatsQueryISOWeeks(t *testing.T) {
+	s := openTest(t)
+	days := map[string]string{
+		"2020-12-31": "2020-W53", // Thursday: its week is 2020's
+		"2021-01-01": "2020-W53", // Friday: still week 53 of 2020
+		"2021-01-03": "2020-W53", // Sunday ends it
+		"2021-01-04": "2021-W01", // Monday starts 2021's first week
+		"2024-12-29": "2024-W52",
+		"2024-12-30": "2025-W01", // Monday in December, week 1 of 2025
+		"2026-12-31": "2026-W53",
+		"2027-01-03": "2026-W53",
+	}
+	for day, want := range days {
+		addInterval(t, s, day+"T12:00:00Z", 25, 0)
+
+		got, err := s.StatsQuery(StatsQuery{From: day, To: day, GroupBy: GroupWeek})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].Key != want {
+			t.Errorf("%s: got %+v, want week %s", day, got, want)
+		}
+	}
+
+	// Filling across the new year must use the same keys as the query, or a
+	// week would show up twice.
+	got, err := s.StatsQuery(StatsQuery{From: "2020-12-21", To: "2021-01-10", GroupBy: GroupWeek, Fill: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Bucket{
+		{Key: "2020-W52", Label: "2020-W52"},
+		{Key: "2020-W53", Label: "2020-W53", Count: 3, FocusMinutes: 75},
+		{Key: "2021-W01", Label: "2021-W01", Count: 1, FocusMinutes: 25},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestParseGroupBy(t *testing.T) {
+	if g, err := ParseGroupBy("Week"); err != nil || g != GroupWeek {
+		t.Errorf("ParseGroupBy(Week) = %q, %v", g, err)
+	}
+	if g, err := ParseGroupBy("weekday-hour"); err != nil || g != GroupWeekdayHour {
+		t.Errorf("ParseGroupBy(weekday-hour) = %q, %v", g, err)
+	}
+	if _, err := ParseGroupBy("year"); err == nil {
+		t.Error("ParseGroupBy(year) succeeded")
+	}
+	if _, err := (&Storage{}).StatsQuery(StatsQuery{GroupBy: "year"}); err == nil {
+		t.Error("StatsQuery with an unknown grouping succeeded")
+	}
+}
+
+// An interval across midnight belongs to the day it started, by weekday as
+// in the weekday-hour heatmap.
+func TestStatsQueryWeekdayAcrossMidnight(t *testing.T) {
+	s := openTest(t)
+	if _, err := s.db.Exec(
+		"INSERT INTO intervals (date, completed_at, started_at, duration_seconds) VALUES (?, ?, ?, ?)",
+		"2026-03-03", "2026-03-03T00:15:00+01:00", "2026-03-02T23:50:00+01:00", 1500,
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	for group, key := range map[GroupBy]string{GroupWeekday: "1", GroupWeekdayHour: "1-23"} {
+		buckets, err := s.StatsQuery(StatsQuery{GroupBy: group})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(buckets) != 1 || buckets[0].Key != key {
+			t.Errorf("%s = %+v, want one bucket %s", group, buckets, key)
+		}
+	}
+}
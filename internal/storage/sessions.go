@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+)
+
+// Session is a completed work interval with everything stored about it. Its
+// JSON form is the export schema, so fields are only ever added.
+type Session struct {
+	ID                    int64    `json:"id"`
+	Date                  string   `json:"date"`
+	StartedAt             string   `json:"started_at"`
+	CompletedAt           string   `json:"completed_at"`
+	DurationSeconds       int      `json:"duration_seconds"`
+	TaskID                *int64   `json:"task_id"`
+	Task                  string   `json:"task"`
+	Project               string   `json:"project"`
+	Note                  string   `json:"note"`
+	Tags                  []string `json:"tags"`
+	InterruptionsInternal int      `json:"interruptions_internal"`
+	InterruptionsExternal int      `json:"interruptions_external"`
+
+	// Interruptions are the interruptions counted above, in the order they
+	// occurred.
+	Interruptions []Interruption `json:"interruptions"`
+}
+
+// Sessions calls fn for each work interval completed between two dates
+// (YYYY-MM-DD, inclusive; empty means unbounded), oldest first. Rows are
+// streamed, so fn sees them before the whole range has been read.
+func (s *Storage) Sessions(from, to string, fn func(Session) error) error {
+	if from == "" {
+		from = "0000-00-00"
+	}
+	if to == "" {
+		to = "9999-99-99"
+	}
+
+	rows, err := s.db.Query(`
+		SELECT
+			i.id, i.date, COALESCE(i.started_at, ''), i.completed_at,
+			COALESCE(i.duration_seconds, 0), i.task_id,
+			COALESCE(t.title, ''), COALESCE(t.project, ''), i.note,
+			COALESCE((SELECT GROUP_CONCAT(tag, ',') FROM (
+				SELECT tag FROM interval_tags WHERE interval_id = i.id ORDER BY tag)), ''),
+			(SELECT COUNT(*) FROM interruptions x WHERE x.interval_id = i.id AND x.kind = 'internal'),
+			(SELECT COUNT(*) FROM interruptions x WHERE x.interval_id = i.id AND x.kind = 'external'),
+			(SELECT json_group_array(json_object('id', id, 'kind', kind, 'note', note, 'occurred_at', occurred_at)) FROM (
+				SELECT * FROM interruptions WHERE interval_id = i.id ORDER BY julianday(occurred_at), id))
+		FROM intervals i
+		LEFT JOIN tasks t ON t.id = i.task_id
+		WHERE i.date BETWEEN ? AND ?
+		ORDER BY i.completed_at, i.id`,
+		from, to,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sess Session
+		var taskID sql.NullInt64
+		var tags, interruptions string
+		if err := rows.Scan(
+			&sess.ID, &sess.Date, &sess.StartedAt, &sess.CompletedAt,
+			&sess.DurationSeconds, &taskID,
+			&sess.Task, &sess.Project, &sess.Note, &tags,
+			&sess.InterruptionsInternal, &sess.InterruptionsExternal,
+			&interruptions,
+		); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(interruptions), &sess.Interruptions); err != nil {
+			return err
+		}
+		if taskID.Valid {
+			sess.TaskID = &taskID.Int64
+		}
+		sess.Tags = splitTags(tags)
+		if err := fn(sess); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
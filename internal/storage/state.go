@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// State returns a value the daemon keeps across restarts, or "" if unset.
+func (s *Storage) State(key string) (string, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM state WHERE key = ?", key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return value, err
+}
+
+func (s *Storage) SetState(key, value string) error {
+	_, err := s.db.Exec(
+		"INSERT INTO state (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value",
+		key, value,
+	)
+	return err
+}
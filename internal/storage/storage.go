@@ -2,11 +2,12 @@ package storage
 
 import (
 	"database/sql"
-	"os"
+	"fmt"
 	"path/filepath"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/philleif/pomme/internal/paths"
 )
 
 type Storage struct {
@@ -18,29 +19,26 @@ type DayStats struct {
 	Intervals int
 }
 
-func dataDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	dir := filepath.Join(home, ".pomme")
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", err
-	}
-	return dir, nil
-}
-
 func New() (*Storage, error) {
-	dir, err := dataDir()
+	dir, err := paths.DataDir()
 	if err != nil {
 		return nil, err
 	}
 
-	dbPath := filepath.Join(dir, "pomme.db")
-	db, err := sql.Open("sqlite3", dbPath)
+	return Open(filepath.Join(dir, "pomme.db"))
+}
+
+// Open opens or creates the database at path. ":memory:" gives a private
+// in-memory database, e.g. for tests.
+func Open(path string) (*Storage, error) {
+	db, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return nil, err
 	}
+	if path == ":memory:" {
+		// Every connection would otherwise get its own empty database.
+		db.SetMaxOpenConns(1)
+	}
 
 	s := &Storage{db: db}
 	if err := s.migrate(); err != nil {
@@ -59,7 +57,90 @@ func (s *Storage) migrate() error {
 			completed_at TEXT NOT NULL
 		);
 		CREATE INDEX IF NOT EXISTS idx_intervals_date ON intervals(date);
+
+		CREATE TABLE IF NOT EXISTS tasks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			title TEXT NOT NULL,
+			project TEXT NOT NULL DEFAULT '',
+			estimate INTEGER NOT NULL DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'active',
+			created_at TEXT NOT NULL,
+			done_at TEXT
+		);
+
+		CREATE TABLE IF NOT EXISTS state (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS interval_tags (
+			interval_id INTEGER NOT NULL REFERENCES intervals(id),
+			tag TEXT NOT NULL,
+			PRIMARY KEY (interval_id, tag)
+		);
+		CREATE INDEX IF NOT EXISTS idx_interval_tags_tag ON interval_tags(tag);
+
+		CREATE TABLE IF NOT EXISTS interruptions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			interval_id INTEGER REFERENCES intervals(id),
+			kind TEXT NOT NULL,
+			note TEXT NOT NULL DEFAULT '',
+			date TEXT NOT NULL,
+			occurred_at TEXT NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_interruptions_date ON interruptions(date);
+
+		CREATE TABLE IF NOT EXISTS goals (
+			effective_from TEXT PRIMARY KEY,
+			goal INTEGER NOT NULL
+		);
 	`)
+	if err != nil {
+		return err
+	}
+
+	columns := []struct{ name, definition string }{
+		{"task_id", "INTEGER REFERENCES tasks(id)"},
+		{"note", "TEXT NOT NULL DEFAULT ''"},
+		{"started_at", "TEXT"},
+		{"duration_seconds", "INTEGER"},
+	}
+	for _, c := range columns {
+		if err := s.addColumn("intervals", c.name, c.definition); err != nil {
+			return err
+		}
+	}
+	if err := s.addColumn("tasks", "taskwarrior_uuid", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumn("goals", "weekday_goals", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	return s.addColumn("goals", "rest_days", "TEXT NOT NULL DEFAULT ''")
+}
+
+// addColumn adds a column to an existing table unless it is already there.
+func (s *Storage) addColumn(table, column, definition string) error {
+	rows, err := s.db.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
 	return err
 }
 
@@ -67,16 +148,61 @@ func (s *Storage) Close() error {
 	return s.db.Close()
 }
 
-func (s *Storage) RecordInterval() error {
+// Interval describes a completed work interval to record.
+type Interval struct {
+	TaskID    int64         // zero for none
+	StartedAt time.Time     // zero if unknown
+	Duration  time.Duration // focused time, excluding pauses
+}
+
+// RecordInterval stores a completed work interval and returns its id.
+// Interruptions logged since it started are linked to it.
+func (s *Storage) RecordInterval(iv Interval) (int64, error) {
 	now := time.Now()
 	date := now.Format("2006-01-02")
 	completedAt := now.Format(time.RFC3339)
 
-	_, err := s.db.Exec(
-		"INSERT INTO intervals (date, completed_at) VALUES (?, ?)",
-		date, completedAt,
+	var startedAt sql.NullString
+	var duration sql.NullInt64
+	if !iv.StartedAt.IsZero() {
+		startedAt = sql.NullString{String: iv.StartedAt.Format(time.RFC3339), Valid: true}
+		duration = sql.NullInt64{Int64: int64(iv.Duration.Seconds()), Valid: true}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		"INSERT INTO intervals (date, completed_at, task_id, started_at, duration_seconds) VALUES (?, ?, ?, ?, ?)",
+		date, completedAt, nullID(iv.TaskID), startedAt, duration,
 	)
-	return err
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	if startedAt.Valid {
+		// Times are stored with the local offset of the moment, which changes
+		// with daylight saving time, so compare them as instants.
+		if _, err := tx.Exec(
+			"UPDATE interruptions SET interval_id = ? WHERE interval_id IS NULL AND julianday(occurred_at) >= julianday(?)",
+			id, iv.StartedAt.UTC().Format(time.RFC3339),
+		); err != nil {
+			return 0, err
+		}
+	}
+
+	return id, tx.Commit()
+}
+
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
 }
 
 func (s *Storage) TodayCount() (int, error) {
@@ -90,35 +216,27 @@ func (s *Storage) TodayCount() (int, error) {
 }
 
 func (s *Storage) Last7Days() ([]DayStats, error) {
-	stats := make([]DayStats, 7)
-	today := time.Now()
-
-	for i := 6; i >= 0; i-- {
-		date := today.AddDate(0, 0, -i)
-		dateStr := date.Format("2006-01-02")
-
-		var count int
-		err := s.db.QueryRow(
-			"SELECT COUNT(*) FROM intervals WHERE date = ?",
-			dateStr,
-		).Scan(&count)
-		if err != nil {
-			return nil, err
-		}
-
-		stats[6-i] = DayStats{
-			Date:      dateStr,
-			Intervals: count,
-		}
-	}
-
-	return stats, nil
+	return s.Last7DaysTagged("")
 }
 
-func SocketPath() string {
-	dir, err := dataDir()
+// Last7DaysTagged is Last7Days counting only intervals carrying tag. An
+// empty tag counts everything.
+func (s *Storage) Last7DaysTagged(tag string) ([]DayStats, error) {
+	today := time.Now()
+	buckets, err := s.StatsQuery(StatsQuery{
+		From:    today.AddDate(0, 0, -6).Format("2006-01-02"),
+		To:      today.Format("2006-01-02"),
+		GroupBy: GroupDay,
+		Tag:     tag,
+		Fill:    true,
+	})
 	if err != nil {
-		return "/tmp/pomme.sock"
+		return nil, err
+	}
+
+	stats := make([]DayStats, len(buckets))
+	for i, b := range buckets {
+		stats[i] = DayStats{Date: b.Key, Intervals: b.Count}
 	}
-	return filepath.Join(dir, "pomme.sock")
+	return stats, nil
 }
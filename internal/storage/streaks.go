@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Streaks summarizes goal achievement over the whole history. A streak is a
+// run of days that met the goal (or, for the Active variants, had at least
+// one pomodoro). Off days, such as rest days, never break a streak but extend
+// it when the goal is met anyway, and today only breaks it once it is over.
+type Streaks struct {
+	Current       int `json:"current"`
+	Longest       int `json:"longest"`
+	ActiveCurrent int `json:"active_current"`
+	ActiveLongest int `json:"active_longest"`
+	GoalDays      int `json:"goal_days"`
+	ActiveDays    int `json:"active_days"`
+
+	// Weeks holds the goal hit rate for recent ISO weeks, oldest first.
+	Weeks []WeekGoalRate `json:"weeks"`
+}
+
+// WeekGoalRate is how many of a week's working (not off) days, so far, met
+// the goal.
+type WeekGoalRate struct {
+	Week     string  `json:"week"`
+	GoalDays int     `json:"goal_days"`
+	WorkDays int     `json:"work_days"`
+	Rate     float64 `json:"rate"`
+}
+
+// Streaks computes streaks up to today with each day's goal from plan. weeks
+// is how many ISO weeks of hit rates to include, ending with the current one.
+func (s *Storage) Streaks(today time.Time, plan GoalPlan, weeks int) (Streaks, error) {
+	var st Streaks
+
+	days, err := s.StatsQuery(StatsQuery{To: today.Format("2006-01-02"), GroupBy: GroupDay})
+	if err != nil {
+		return st, err
+	}
+
+	counts := make(map[string]int, len(days))
+	for _, d := range days {
+		counts[d.Key] = d.Count
+	}
+	hit := func(date string) bool {
+		goal := plan.On(date).Goal
+		return goal > 0 && counts[date] >= goal
+	}
+	isOff := func(d time.Time) bool {
+		return plan.On(d.Format("2006-01-02")).Off
+	}
+
+	end := dayStart(today)
+	if len(days) > 0 {
+		start, err := time.ParseInLocation("2006-01-02", days[0].Key, time.Local)
+		if err != nil {
+			return st, fmt.Errorf("invalid date %q", days[0].Key)
+		}
+
+		var run, activeRun int
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			date := d.Format("2006-01-02")
+			pending := d.Equal(end) || isOff(d)
+
+			if hit(date) {
+				run++
+				st.GoalDays++
+			} else if !pending {
+				run = 0
+			}
+			if counts[date] > 0 {
+				activeRun++
+				st.ActiveDays++
+			} else if !pending {
+				activeRun = 0
+			}
+			st.Longest = max(st.Longest, run)
+			st.ActiveLongest = max(st.ActiveLongest, activeRun)
+		}
+		st.Current, st.ActiveCurrent = run, activeRun
+	}
+
+	// Days before the plan's first goal have no goal to miss, and today only
+	// counts once its goal is met.
+	planStart, err := time.ParseInLocation("2006-01-02", plan.Start(), time.Local)
+	if err != nil {
+		planStart = end
+	}
+
+	// Monday of the current ISO week, then back weeks-1 weeks.
+	monday := end.AddDate(0, 0, -((int(end.Weekday()) + 6) % 7))
+	for w := weeks - 1; w >= 0; w-- {
+		first := monday.AddDate(0, 0, -7*w)
+		year, week := first.ISOWeek()
+		rate := WeekGoalRate{Week: fmt.Sprintf("%d-W%02d", year, week)}
+		for d := first; d.Before(first.AddDate(0, 0, 7)) && !d.After(end); d = d.AddDate(0, 0, 1) {
+			date := d.Format("2006-01-02")
+			if d.Before(planStart) || isOff(d) || (d.Equal(end) && !hit(date)) {
+				continue
+			}
+			rate.WorkDays++
+			if hit(date) {
+				rate.GoalDays++
+			}
+		}
+		if rate.WorkDays > 0 {
+			rate.Rate = float64(rate.GoalDays) / float64(rate.WorkDays)
+		}
+		st.Weeks = append(st.Weeks, rate)
+	}
+
+	return st, nil
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStreakWeeks(t *testing.T) {
+	s := openTest(t)
+	for _, start := range []string{
+		"2026-03-03T10:00:00Z", "2026-03-03T11:00:00Z", // Tue: goal met
+		"2026-03-04T10:00:00Z",                         // Wed: missed
+		"2026-03-09T10:00:00Z", "2026-03-09T11:00:00Z", // Mon: goal met
+		"2026-03-11T10:00:00Z", // today, under way
+	} {
+		addInterval(t, s, start, 25, 0)
+	}
+	today := time.Date(2026, 3, 11, 18, 0, 0, 0, time.Local)
+	plan := GoalPlan{
+		History: []GoalChange{{From: "2026-03-03", Goal: 2}},
+		Current: GoalChange{From: "2026-03-11", Goal: 2},
+	}
+
+	st, err := s.Streaks(today, plan, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Days before the first goal aren't missed, and today isn't yet.
+	want := []WeekGoalRate{
+		{Week: "2026-W09"},
+		{Week: "2026-W10", GoalDays: 1, WorkDays: 6, Rate: 1.0 / 6},
+		{Week: "2026-W11", GoalDays: 1, WorkDays: 2, Rate: 0.5},
+	}
+	if !reflect.DeepEqual(st.Weeks, want) {
+		t.Errorf("weeks = %+v, want %+v", st.Weeks, want)
+	}
+
+	// Today counts once its goal is met.
+	addInterval(t, s, "2026-03-11T14:00:00Z", 25, 0)
+	st, err = s.Streaks(today, plan, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := st.Weeks[0]; got.GoalDays != 2 || got.WorkDays != 3 {
+		t.Errorf("this week = %+v, want 2 of 3 days", got)
+	}
+
+	// A fresh install has nothing to miss yet.
+	st, err = openTest(t).Streaks(today, GoalPlan{Current: GoalChange{From: "2026-03-11", Goal: 2}}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range st.Weeks {
+		if w.WorkDays != 0 {
+			t.Errorf("fresh install %s = %d/%d, want no work days", w.Week, w.GoalDays, w.WorkDays)
+		}
+	}
+}
+
+// Rest days are part of the goal history, so changing them leaves past
+// weeks as they were.
+func TestRecordGoalRestDays(t *testing.T) {
+	s := openTest(t)
+	weekend := GoalChange{From: "2026-03-01", Goal: 4, RestDays: []time.Weekday{time.Sunday, time.Saturday}}
+	if err := s.RecordGoal(weekend); err != nil {
+		t.Fatal(err)
+	}
+	// The same rest days in another order are no change.
+	if err := s.RecordGoal(GoalChange{From: "2026-03-05", Goal: 4, RestDays: []time.Weekday{time.Saturday, time.Sunday}}); err != nil {
+		t.Fatal(err)
+	}
+	// Dropping them is.
+	if err := s.RecordGoal(GoalChange{From: "2026-03-10", Goal: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := s.Goals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GoalChange{
+		{From: "2026-03-01", Goal: 4, RestDays: []time.Weekday{time.Sunday, time.Saturday}},
+		{From: "2026-03-10", Goal: 4},
+	}
+	if !reflect.DeepEqual(history, want) {
+		t.Fatalf("history = %+v, want %+v", history, want)
+	}
+
+	plan, err := s.GoalPlan(GoalChange{From: "2026-03-12", Goal: 4}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := plan.On("2026-03-07"); !got.Off || got.Goal != 4 {
+		t.Errorf("Saturday before the change = %+v, want a rest day with goal 4", got)
+	}
+	if got := plan.On("2026-03-14"); got.Off {
+		t.Errorf("Saturday after the change = %+v, want a work day", got)
+	}
+}
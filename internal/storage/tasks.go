@@ -0,0 +1,172 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	TaskActive = "active"
+	TaskDone   = "done"
+)
+
+type Task struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	Project   string `json:"project,omitempty"`
+	Estimate  int    `json:"estimate"`
+	Completed int    `json:"completed"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	DoneAt    string `json:"done_at,omitempty"`
+
+	// TaskwarriorUUID links the task to a Taskwarrior task it was picked from.
+	TaskwarriorUUID string `json:"taskwarrior_uuid,omitempty"`
+}
+
+var ErrTaskNotFound = errors.New("task not found")
+
+const taskColumns = `
+	t.id, t.title, t.project, t.estimate,
+	(SELECT COUNT(*) FROM intervals i WHERE i.task_id = t.id),
+	t.status, t.created_at, COALESCE(t.done_at, ''), COALESCE(t.taskwarrior_uuid, '')`
+
+func scanTask(row interface{ Scan(...any) error }) (Task, error) {
+	var t Task
+	err := row.Scan(&t.ID, &t.Title, &t.Project, &t.Estimate, &t.Completed, &t.Status, &t.CreatedAt, &t.DoneAt, &t.TaskwarriorUUID)
+	return t, err
+}
+
+func (s *Storage) AddTask(title, project string, estimate int) (Task, error) {
+	if title == "" {
+		return Task{}, errors.New("task title is required")
+	}
+	if estimate < 0 {
+		return Task{}, errors.New("estimate must not be negative")
+	}
+
+	res, err := s.db.Exec(
+		"INSERT INTO tasks (title, project, estimate, status, created_at) VALUES (?, ?, ?, ?, ?)",
+		title, project, estimate, TaskActive, time.Now().Format(time.RFC3339),
+	)
+	if err != nil {
+		return Task{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return Task{}, err
+	}
+	return s.Task(id)
+}
+
+// TaskwarriorTask returns the task linked to a Taskwarrior UUID, creating it
+// if needed. An existing task takes on the current title and project and is
+// made active again.
+func (s *Storage) TaskwarriorTask(uuid, title, project string) (Task, error) {
+	if uuid == "" {
+		return Task{}, errors.New("taskwarrior uuid is required")
+	}
+
+	var id int64
+	err := s.db.QueryRow("SELECT id FROM tasks WHERE taskwarrior_uuid = ?", uuid).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		res, err := s.db.Exec(
+			"INSERT INTO tasks (title, project, status, created_at, taskwarrior_uuid) VALUES (?, ?, ?, ?, ?)",
+			title, project, TaskActive, time.Now().Format(time.RFC3339), uuid,
+		)
+		if err != nil {
+			return Task{}, err
+		}
+		if id, err = res.LastInsertId(); err != nil {
+			return Task{}, err
+		}
+		return s.Task(id)
+	}
+	if err != nil {
+		return Task{}, err
+	}
+
+	if _, err := s.db.Exec(
+		"UPDATE tasks SET title = ?, project = ?, status = ?, done_at = NULL WHERE id = ?",
+		title, project, TaskActive, id,
+	); err != nil {
+		return Task{}, err
+	}
+	return s.Task(id)
+}
+
+func (s *Storage) Task(id int64) (Task, error) {
+	t, err := scanTask(s.db.QueryRow("SELECT "+taskColumns+" FROM tasks t WHERE t.id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Task{}, fmt.Errorf("%w: %d", ErrTaskNotFound, id)
+	}
+	return t, err
+}
+
+// Tasks lists active tasks, oldest first, or every task when includeDone is
+// set.
+func (s *Storage) Tasks(includeDone bool) ([]Task, error) {
+	query := "SELECT " + taskColumns + " FROM tasks t"
+	if !includeDone {
+		query += " WHERE t.status = '" + TaskActive + "'"
+	}
+	query += " ORDER BY t.id"
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+func (s *Storage) CompleteTask(id int64) (Task, error) {
+	res, err := s.db.Exec(
+		"UPDATE tasks SET status = ?, done_at = ? WHERE id = ?",
+		TaskDone, time.Now().Format(time.RFC3339), id,
+	)
+	if err != nil {
+		return Task{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return Task{}, fmt.Errorf("%w: %d", ErrTaskNotFound, id)
+	}
+	return s.Task(id)
+}
+
+// CurrentTaskID returns the task selected on the daemon, or 0 for none.
+func (s *Storage) CurrentTaskID() (int64, error) {
+	var value string
+	err := s.db.QueryRow("SELECT value FROM state WHERE key = 'current_task'").Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func (s *Storage) SetCurrentTaskID(id int64) error {
+	if id == 0 {
+		_, err := s.db.Exec("DELETE FROM state WHERE key = 'current_task'")
+		return err
+	}
+	_, err := s.db.Exec(
+		"INSERT INTO state (key, value) VALUES ('current_task', ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value",
+		strconv.FormatInt(id, 10),
+	)
+	return err
+}
@@ -0,0 +1,155 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Custom is a theme defined in the config: the built-in theme Base, or
+// Default when empty, with some of its colors changed. In JSON it is one
+// object with "base" beside the colors:
+//
+//	{"base": "light", "accent": "#8250df", "work": "#1a7f37"}
+type Custom struct {
+	Base   string
+	Colors map[string]string // by ParseColors name
+}
+
+func (c Custom) base() string {
+	if c.Base == "" {
+		return Default
+	}
+	return c.Base
+}
+
+func (c Custom) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]string, len(c.Colors)+1)
+	for key, value := range c.Colors {
+		fields[key] = value
+	}
+	if c.Base != "" {
+		fields["base"] = c.Base
+	}
+	return json.Marshal(fields)
+}
+
+func (c *Custom) UnmarshalJSON(data []byte) error {
+	var fields map[string]string
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf(`a theme is an object of colors, like {"base": "light", "accent": "#8250df"}`)
+	}
+	*c = Custom{Colors: make(map[string]string, len(fields))}
+	for key, value := range fields {
+		if key == "base" {
+			c.Base = value
+			continue
+		}
+		c.Colors[strings.ToLower(key)] = value
+	}
+	return nil
+}
+
+// Customs are custom themes by name, the config's "themes" object.
+type Customs map[string]Custom
+
+// String is the JSON form, so config get and list show it as it is set.
+func (cs Customs) String() string {
+	data, _ := json.Marshal(map[string]Custom(cs))
+	return string(data)
+}
+
+func (cs *Customs) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf(`expected an object of themes by name, like {"mine": {"base": "light", "accent": "#8250df"}}`)
+	}
+	m := make(Customs, len(raw))
+	for name, value := range raw {
+		var c Custom
+		if err := json.Unmarshal(value, &c); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		m[name] = c
+	}
+	*cs = m
+	return nil
+}
+
+// UnmarshalText reads the JSON form; empty means no custom themes.
+func (cs *Customs) UnmarshalText(text []byte) error {
+	if strings.TrimSpace(string(text)) == "" {
+		*cs = Customs{}
+		return nil
+	}
+	return cs.UnmarshalJSON(text)
+}
+
+// Check reports the first custom theme, in name order, that shadows a
+// built-in or another custom theme, has an unknown base or an invalid
+// color. Names are matched ignoring case, like Load does.
+func (cs Customs) Check() error {
+	seen := make(map[string]string, len(cs))
+	for _, name := range sortedKeys(cs) {
+		c := cs[name]
+		if _, ok := Themes[strings.ToLower(name)]; ok {
+			return fmt.Errorf("%s: a built-in theme has that name", name)
+		}
+		if other, ok := seen[strings.ToLower(name)]; ok {
+			return fmt.Errorf("%s: theme %s has the same name", name, other)
+		}
+		seen[strings.ToLower(name)] = name
+		if _, ok := Themes[strings.ToLower(c.base())]; !ok {
+			return fmt.Errorf("%s: unknown base %q (use %s)", name, c.Base, strings.Join(Names(), ", "))
+		}
+		for _, key := range sortedKeys(c.Colors) {
+			if err := checkColor(key, c.Colors[key]); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Has reports whether name is a built-in theme or one of cs, ignoring
+// case, so it accepts the names Load does.
+func (cs Customs) Has(name string) bool {
+	if _, ok := Themes[strings.ToLower(name)]; ok {
+		return true
+	}
+	_, ok := cs.find(name)
+	return ok
+}
+
+// find returns the custom theme called name, ignoring case.
+func (cs Customs) find(name string) (Custom, bool) {
+	if c, ok := cs[name]; ok {
+		return c, true
+	}
+	for key, c := range cs {
+		if strings.EqualFold(key, name) {
+			return c, true
+		}
+	}
+	return Custom{}, false
+}
+
+// Names lists the built-in themes and cs, sorted.
+func (cs Customs) Names() []string {
+	names := Names()
+	for name := range cs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,380 @@
+// Package theme holds pomme's color schemes. A theme is a set of named
+// colors used by the TUI, the pixel graphs, the calendar and heatmap, image
+// charts and tmux status markup. Built-in themes can be adjusted color by
+// color with ParseColors, and NO_COLOR turns color off everywhere.
+package theme
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/philleif/pomme/internal/chart"
+	"github.com/philleif/pomme/internal/sparkline"
+)
+
+// Theme colors are "#rrggbb"; an empty color means the terminal's default.
+type Theme struct {
+	Accent    string // title, today, current task, errors, goals met
+	Text      string
+	Secondary string // stats
+	Muted     string // counters, phase
+	Subtle    string // labels, help, goal marks
+	Faint     string // empty progress
+	Good      string // sparkline, toggles on
+
+	// Timer background and box border by state.
+	Work   string
+	Break  string
+	Paused string
+	Idle   string
+
+	// Pixel graphs; goals met use Accent.
+	Bar      string
+	Overflow string
+	Goal     string
+	Axis     string
+
+	// Calendar and heatmap; the busiest cells use Accent.
+	Empty   string
+	HeatLow string
+
+	// Background of image charts; empty is transparent.
+	Background string
+}
+
+// Default is the theme used when none is configured.
+const Default = "dark"
+
+var Themes = map[string]Theme{
+	"dark": {
+		Accent:     "#FF6347",
+		Text:       "#FFFFFF",
+		Secondary:  "#AAAAAA",
+		Muted:      "#888888",
+		Subtle:     "#666666",
+		Faint:      "#333333",
+		Good:       "#73F59F",
+		Work:       "#00FF00",
+		Break:      "#4A90D9",
+		Paused:     "#888888",
+		Idle:       "#FF6347",
+		Bar:        "#F4A99B",
+		Overflow:   "#FFD700",
+		Goal:       "#969696",
+		Axis:       "#646464",
+		Empty:      "#303030",
+		HeatLow:    "#6E2D26",
+		Background: "#0D1117",
+	},
+	"light": {
+		Accent:     "#D9381E",
+		Text:       "#1F2328",
+		Secondary:  "#57606A",
+		Muted:      "#6E7781",
+		Subtle:     "#8C959F",
+		Faint:      "#D0D7DE",
+		Good:       "#1A7F37",
+		Work:       "#2DA44E",
+		Break:      "#0969DA",
+		Paused:     "#8C959F",
+		Idle:       "#D9381E",
+		Bar:        "#F4A99B",
+		Overflow:   "#BF8700",
+		Goal:       "#57606A",
+		Axis:       "#8C959F",
+		Empty:      "#EBEDF0",
+		HeatLow:    "#FBD3CB",
+		Background: "#FFFFFF",
+	},
+	// high-contrast keeps to saturated colors and white on black.
+	"high-contrast": {
+		Accent:     "#FF5F5F",
+		Text:       "#FFFFFF",
+		Secondary:  "#FFFFFF",
+		Muted:      "#D0D0D0",
+		Subtle:     "#B0B0B0",
+		Faint:      "#5F5F5F",
+		Good:       "#00FF00",
+		Work:       "#00FF00",
+		Break:      "#00FFFF",
+		Paused:     "#FFFFFF",
+		Idle:       "#FF0000",
+		Bar:        "#FFFFFF",
+		Overflow:   "#FF00FF",
+		Goal:       "#FFFF00",
+		Axis:       "#FFFFFF",
+		Empty:      "#262626",
+		HeatLow:    "#5F0000",
+		Background: "#000000",
+	},
+	// colorblind uses the Okabe-Ito palette, which stays distinct with
+	// red-green color blindness: orange against blues, no red/green pairs.
+	"colorblind": {
+		Accent:     "#E69F00",
+		Text:       "#FFFFFF",
+		Secondary:  "#AAAAAA",
+		Muted:      "#888888",
+		Subtle:     "#666666",
+		Faint:      "#333333",
+		Good:       "#56B4E9",
+		Work:       "#009E73",
+		Break:      "#56B4E9",
+		Paused:     "#888888",
+		Idle:       "#D55E00",
+		Bar:        "#0072B2",
+		Overflow:   "#CC79A7",
+		Goal:       "#BBBBBB",
+		Axis:       "#777777",
+		Empty:      "#303030",
+		HeatLow:    "#3D2A00",
+		Background: "#1B1B1B",
+	},
+}
+
+// plain is the theme under NO_COLOR: no text colors, and grays for the
+// graphs, which can't do without.
+var plain = Theme{
+	Bar:      "#A0A0A0",
+	Overflow: "#FFFFFF",
+	Goal:     "#707070",
+	Axis:     "#707070",
+	Empty:    "#303030",
+	HeatLow:  "#505050",
+}
+
+// Names lists the built-in themes, sorted.
+func Names() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NoColor reports whether the NO_COLOR convention asks for no color.
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// Load returns theme name, a built-in or one of customs, with colors, a
+// ParseColors list, applied on top. Under NO_COLOR it returns the plain
+// theme instead.
+func Load(name string, customs Customs, colors string) (Theme, error) {
+	var custom map[string]string
+	base := name
+	if c, ok := customs.find(name); ok {
+		base, custom = c.base(), c.Colors
+	}
+	t, ok := Themes[strings.ToLower(base)]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (use %s)", name, strings.Join(customs.Names(), ", "))
+	}
+	overrides, err := ParseColors(colors)
+	if err != nil {
+		return Theme{}, err
+	}
+	if NoColor() {
+		return plain, nil
+	}
+	for key, c := range custom {
+		if f := t.field(key); f != nil {
+			*f = c
+		}
+	}
+	for key, c := range overrides {
+		*t.field(key) = c
+	}
+	return t, nil
+}
+
+// keys are the names of Theme's colors in a ParseColors list.
+var keys = []string{
+	"accent", "text", "secondary", "muted", "subtle", "faint", "good",
+	"work", "break", "paused", "idle",
+	"bar", "overflow", "goal", "axis",
+	"empty", "heat_low", "background",
+}
+
+func (t *Theme) field(key string) *string {
+	switch key {
+	case "accent":
+		return &t.Accent
+	case "text":
+		return &t.Text
+	case "secondary":
+		return &t.Secondary
+	case "muted":
+		return &t.Muted
+	case "subtle":
+		return &t.Subtle
+	case "faint":
+		return &t.Faint
+	case "good":
+		return &t.Good
+	case "work":
+		return &t.Work
+	case "break":
+		return &t.Break
+	case "paused":
+		return &t.Paused
+	case "idle":
+		return &t.Idle
+	case "bar":
+		return &t.Bar
+	case "overflow":
+		return &t.Overflow
+	case "goal":
+		return &t.Goal
+	case "axis":
+		return &t.Axis
+	case "empty":
+		return &t.Empty
+	case "heat_low":
+		return &t.HeatLow
+	case "background":
+		return &t.Background
+	}
+	return nil
+}
+
+// ParseColors parses a comma-separated list of color overrides such as
+// "accent=#e69f00,bar=#0072b2". Empty means none.
+func ParseColors(s string) (map[string]string, error) {
+	colors := make(map[string]string)
+	if strings.TrimSpace(s) == "" {
+		return colors, nil
+	}
+	for _, part := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid theme color %q (use name=#rrggbb)", part)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if err := checkColor(key, value); err != nil {
+			return nil, err
+		}
+		colors[key] = value
+	}
+	return colors, nil
+}
+
+// checkColor checks that key names a Theme color and value is one.
+func checkColor(key, value string) error {
+	if (&Theme{}).field(key) == nil {
+		return fmt.Errorf("unknown theme color %q (use %s)", key, strings.Join(keys, ", "))
+	}
+	if _, err := parseHex(value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+func parseHex(s string) (color.NRGBA, error) {
+	hex, ok := strings.CutPrefix(s, "#")
+	if !ok || len(hex) != 6 {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q (use #rrggbb)", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("invalid color %q (use #rrggbb)", s)
+	}
+	return color.NRGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
+
+// RGB converts a theme color for drawing; the terminal default has no RGB,
+// so empty gives fallback.
+func RGB(c string, fallback color.NRGBA) color.NRGBA {
+	if rgb, err := parseHex(c); err == nil {
+		return rgb
+	}
+	return fallback
+}
+
+// Contrast is black or white, whichever reads better on background c.
+func Contrast(c string) string {
+	rgb, err := parseHex(c)
+	if err != nil {
+		return ""
+	}
+	if 299*int(rgb.R)+587*int(rgb.G)+114*int(rgb.B) > 128*1000 {
+		return "#000000"
+	}
+	return "#FFFFFF"
+}
+
+// BarColors are the colors of a pixel graph in t.
+func (t Theme) BarColors() sparkline.BarColors {
+	def := sparkline.DefaultBarColors()
+	today := RGB(t.Muted, color.NRGBA{128, 128, 128, 255})
+	today.A = 48
+	goal := RGB(t.Goal, def.Goal)
+	goal.A = 200
+	return sparkline.BarColors{
+		Bar:        RGB(t.Bar, def.Bar),
+		BarGoalMet: RGB(t.Accent, RGB(t.Overflow, def.BarGoalMet)),
+		Overflow:   RGB(t.Overflow, def.Overflow),
+		Goal:       goal,
+		Axis:       RGB(t.Axis, def.Axis),
+		Today:      today,
+	}
+}
+
+// Palette is the calendar and heatmap palette of t.
+func (t Theme) Palette() sparkline.Palette {
+	def := sparkline.DefaultPalette()
+	return sparkline.Palette{
+		Empty: RGB(t.Empty, def.Empty),
+		Low:   RGB(t.HeatLow, def.Low),
+		High:  RGB(t.Accent, RGB(t.Overflow, def.High)),
+	}
+}
+
+// Chart is the palette of image charts in t. Without a color, as under
+// NO_COLOR, text and lines are a mid gray that reads on light and dark.
+func (t Theme) Chart() chart.Theme {
+	gray := color.NRGBA{128, 128, 128, 255}
+	empty := RGB(t.Empty, color.NRGBA{128, 128, 128, 64})
+	return chart.Theme{
+		Background: RGB(t.Background, color.NRGBA{}),
+		Text:       RGB(t.Text, gray),
+		Muted:      RGB(t.Muted, gray),
+		Grid:       RGB(t.Axis, gray),
+		Bar:        RGB(t.Bar, gray),
+		BarGoalMet: RGB(t.Accent, RGB(t.Overflow, gray)),
+		Overflow:   RGB(t.Overflow, gray),
+		Goal:       RGB(t.Goal, gray),
+		Empty:      empty,
+		HeatLow:    RGB(t.HeatLow, gray),
+		Heat:       RGB(t.Accent, RGB(t.Overflow, gray)),
+	}
+}
+
+// Colors are t's colors by their ParseColors names; under NO_COLOR most
+// are empty.
+func (t Theme) Colors() map[string]string {
+	colors := make(map[string]string, len(keys))
+	for _, key := range keys {
+		colors[key] = *t.field(key)
+	}
+	return colors
+}
+
+// StateColor is t's color for a timer state: "work", "break", "paused" or
+// anything else for idle.
+func (t Theme) StateColor(state string) string {
+	switch state {
+	case "work":
+		return t.Work
+	case "break":
+		return t.Break
+	case "paused":
+		return t.Paused
+	}
+	return t.Idle
+}
@@ -0,0 +1,172 @@
+package theme
+
+import (
+	"encoding/json"
+	"image/color"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/philleif/pomme/internal/sparkline"
+)
+
+func TestLoadCustom(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	customs := Customs{
+		"mine":  {Base: "light", Colors: map[string]string{"accent": "#8250DF", "background": "#FAFAFA"}},
+		"plain": {Colors: map[string]string{"work": "#123456"}},
+	}
+
+	got, err := Load("mine", customs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Themes["light"]
+	want.Accent, want.Background = "#8250DF", "#FAFAFA"
+	if got != want {
+		t.Errorf("mine = %+v, want light with accent and background changed", got)
+	}
+
+	// Without a base a custom theme starts from the default.
+	got, err = Load("plain", customs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = Themes[Default]
+	want.Work = "#123456"
+	if got != want {
+		t.Errorf("plain = %+v, want %s with work changed", got, Default)
+	}
+
+	// theme_colors goes on top of a custom theme.
+	got, err = Load("mine", customs, "accent=#000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Accent != "#000000" || got.Background != "#FAFAFA" {
+		t.Errorf("accent %s, background %s: want theme_colors over the custom theme", got.Accent, got.Background)
+	}
+
+	// Names ignore case, for custom themes as for built-ins.
+	if got, err := Load("Mine", customs, ""); err != nil || got.Accent != "#8250DF" {
+		t.Errorf("Load(Mine) = %+v, %v, want mine", got, err)
+	}
+
+	if _, err := Load("yours", customs, ""); err == nil || !strings.Contains(err.Error(), "mine") {
+		t.Errorf("Load(yours) = %v, want an error listing custom themes", err)
+	}
+}
+
+func TestLoadNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	customs := Customs{"mine": {Base: "light", Colors: map[string]string{"accent": "#8250DF"}}}
+	got, err := Load("mine", customs, "accent=#000000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != plain {
+		t.Errorf("NO_COLOR gave %+v, want the plain theme", got)
+	}
+	if _, err := Load("mine", customs, "accent=red"); err == nil {
+		t.Error("NO_COLOR skipped checking theme_colors")
+	}
+}
+
+func TestCustomsJSON(t *testing.T) {
+	data := `{"mine": {"base": "light", "accent": "#8250df", "Heat_Low": "#fbd3cb"}, "night": {"bar": "#333333"}}`
+	var customs Customs
+	if err := json.Unmarshal([]byte(data), &customs); err != nil {
+		t.Fatal(err)
+	}
+	want := Customs{
+		"mine":  {Base: "light", Colors: map[string]string{"accent": "#8250df", "heat_low": "#fbd3cb"}},
+		"night": {Colors: map[string]string{"bar": "#333333"}},
+	}
+	if !reflect.DeepEqual(customs, want) {
+		t.Errorf("decoded %+v, want %+v", customs, want)
+	}
+	if err := customs.Check(); err != nil {
+		t.Error(err)
+	}
+
+	out := customs.String()
+	if out != `{"mine":{"accent":"#8250df","base":"light","heat_low":"#fbd3cb"},"night":{"bar":"#333333"}}` {
+		t.Errorf("String = %s", out)
+	}
+	var again Customs
+	if err := again.UnmarshalText([]byte(out)); err != nil || !reflect.DeepEqual(again, want) {
+		t.Errorf("round trip = %+v, %v", again, err)
+	}
+	if err := again.UnmarshalText(nil); err != nil || len(again) != 0 {
+		t.Errorf("empty text = %+v, %v; want no themes", again, err)
+	}
+
+	for _, bad := range []string{`"mine"`, `{"mine": "light"}`, `{"mine": {"accent": 1}}`} {
+		var c Customs
+		if err := json.Unmarshal([]byte(bad), &c); err == nil {
+			t.Errorf("%s decoded as %+v", bad, c)
+		}
+	}
+}
+
+func TestCustomsCheck(t *testing.T) {
+	tests := []struct {
+		customs Customs
+		want    string
+	}{
+		{Customs{"Dark": {}}, "built-in"},
+		{Customs{"mine": {Base: "mine"}}, `unknown base "mine"`},
+		{Customs{"mine": {Colors: map[string]string{"glow": "#ffffff"}}}, `unknown theme color "glow"`},
+		{Customs{"mine": {Colors: map[string]string{"accent": "orange"}}}, "invalid color"},
+	}
+	for _, tt := range tests {
+		err := tt.customs.Check()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Check(%+v) = %v, want %q", tt.customs, err, tt.want)
+		}
+	}
+	if names := (Customs{"mine": {}}).Names(); !reflect.DeepEqual(names, []string{"colorblind", "dark", "high-contrast", "light", "mine"}) {
+		t.Errorf("Names = %v", names)
+	}
+}
+
+// Graphs, calendars and charts follow the theme rather than fixed defaults.
+func TestThemeGraphColors(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	th, err := Load("colorblind", nil, "heat_low=#010203")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accent := color.NRGBA{0xE6, 0x9F, 0x00, 255}
+
+	if got := th.BarColors(); got.Bar != (color.NRGBA{0x00, 0x72, 0xB2, 255}) || got.BarGoalMet != accent {
+		t.Errorf("BarColors = %+v", got)
+	}
+	if got := th.Palette(); got.Low != (color.NRGBA{1, 2, 3, 255}) || got.High != accent {
+		t.Errorf("Palette = %+v", got)
+	}
+	if got := th.Chart(); got.Background != (color.NRGBA{0x1B, 0x1B, 0x1B, 255}) || got.Heat != accent {
+		t.Errorf("Chart = %+v", got)
+	}
+
+	p := th.Palette()
+	cell := sparkline.RenderHeatmap([][]int{{0, 4}}, nil, nil, sparkline.HeatmapColor, p)
+	if !strings.Contains(cell, "\x1b[48;2;230;159;0m") {
+		t.Errorf("heatmap %q does not use the theme's accent for the busiest cell", cell)
+	}
+	day := []sparkline.CalendarDay{{Date: "2026-10-12", Count: 8, Goal: 8}}
+	if cal := sparkline.RenderCalendar(day, sparkline.CalendarANSI, p); !strings.Contains(cal, "\x1b[38;2;230;159;0m") {
+		t.Errorf("calendar %q does not use the theme's accent for a goal met", cal)
+	}
+}
+
+func TestChartWithoutColors(t *testing.T) {
+	gray := color.NRGBA{128, 128, 128, 255}
+	got := Theme{}.Chart()
+	if got.Background.A != 0 {
+		t.Errorf("no background color gave %v, want transparent", got.Background)
+	}
+	if got.Text != gray || got.Grid != gray {
+		t.Errorf("text %v, grid %v: want mid gray", got.Text, got.Grid)
+	}
+}
@@ -73,6 +73,14 @@ type Timer struct {
 	intervalsToday  int
 	intervalsSinceBreak int
 
+	// phaseStartedAt is when the current phase first started running and
+	// phaseElapsed how long it has run, excluding pauses. The last* fields
+	// hold the same for the most recently completed phase.
+	phaseStartedAt   time.Time
+	phaseElapsed     time.Duration
+	lastStartedAt    time.Time
+	lastPhaseElapsed time.Duration
+
 	lastTick   time.Time
 	onComplete func(phase Phase)
 	stopChan   chan struct{}
@@ -87,6 +95,31 @@ func New(config Config) *Timer {
 	}
 }
 
+// SetConfig replaces the timer configuration. New durations apply from the
+// next phase; an idle timer that hasn't been touched picks them up at once.
+func (t *Timer) SetConfig(config Config) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.state == StateIdle && t.remaining == t.durationFor(t.phase) {
+		t.config = config
+		t.remaining = t.durationFor(t.phase)
+		return
+	}
+	t.config = config
+}
+
+func (t *Timer) durationFor(phase Phase) time.Duration {
+	switch phase {
+	case PhaseShortBreak:
+		return t.config.ShortBreakDuration
+	case PhaseLongBreak:
+		return t.config.LongBreakDuration
+	default:
+		return t.config.WorkDuration
+	}
+}
+
 func (t *Timer) SetOnComplete(fn func(phase Phase)) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -103,6 +136,9 @@ func (t *Timer) Start() {
 
 	t.state = StateRunning
 	t.lastTick = time.Now()
+	if t.phaseStartedAt.IsZero() {
+		t.phaseStartedAt = t.lastTick
+	}
 	t.stopChan = make(chan struct{})
 
 	go t.run()
@@ -123,9 +159,7 @@ func (t *Timer) run() {
 				continue
 			}
 
-			elapsed := now.Sub(t.lastTick)
-			t.lastTick = now
-			t.remaining -= elapsed
+			t.tick(now)
 
 			if t.remaining <= 0 {
 				completedPhase := t.phase
@@ -143,7 +177,23 @@ func (t *Timer) run() {
 	}
 }
 
+// tick counts the time since the last tick as run.
+func (t *Timer) tick(now time.Time) {
+	elapsed := now.Sub(t.lastTick)
+	t.lastTick = now
+	t.remaining -= elapsed
+	t.phaseElapsed += elapsed
+}
+
 func (t *Timer) advancePhase() {
+	t.lastStartedAt = t.phaseStartedAt
+	t.lastPhaseElapsed = t.phaseElapsed
+	t.phaseElapsed = 0
+	t.phaseStartedAt = time.Time{}
+	if t.state == StateRunning {
+		t.phaseStartedAt = time.Now()
+	}
+
 	if t.phase == PhaseWork {
 		t.intervalsToday++
 		t.intervalsSinceBreak++
@@ -167,6 +217,7 @@ func (t *Timer) Pause() {
 	defer t.mu.Unlock()
 
 	if t.state == StateRunning {
+		t.tick(time.Now())
 		t.state = StatePaused
 		if t.stopChan != nil {
 			close(t.stopChan)
@@ -188,13 +239,17 @@ func (t *Timer) Skip() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	if t.state == StateRunning && t.stopChan != nil {
-		close(t.stopChan)
+	if t.state == StateRunning {
+		t.tick(time.Now())
+		if t.stopChan != nil {
+			close(t.stopChan)
+		}
 	}
 
 	completedPhase := t.phase
 	t.advancePhase()
 	t.state = StateIdle
+	t.phaseStartedAt = time.Time{}
 
 	if t.onComplete != nil {
 		go t.onComplete(completedPhase)
@@ -213,6 +268,16 @@ func (t *Timer) Reset() {
 	t.phase = PhaseWork
 	t.remaining = t.config.WorkDuration
 	t.intervalsSinceBreak = 0
+	t.phaseStartedAt = time.Time{}
+	t.phaseElapsed = 0
+}
+
+// LastPhase reports when the most recently completed phase started and how
+// long it ran, excluding pauses.
+func (t *Timer) LastPhase() (startedAt time.Time, elapsed time.Duration) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.lastStartedAt, t.lastPhaseElapsed
 }
 
 func (t *Timer) State() State {
@@ -250,6 +315,7 @@ type Status struct {
 	Phase          Phase
 	Remaining      time.Duration
 	IntervalsToday int
+	PhaseStartedAt time.Time // zero until the phase first starts running
 }
 
 func (t *Timer) Status() Status {
@@ -260,5 +326,6 @@ func (t *Timer) Status() Status {
 		Phase:          t.phase,
 		Remaining:      t.remaining,
 		IntervalsToday: t.intervalsToday,
+		PhaseStartedAt: t.phaseStartedAt,
 	}
 }
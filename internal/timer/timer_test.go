@@ -0,0 +1,25 @@
+package timer
+
+import (
+	"testing"
+	"time"
+)
+
+// Pause and Skip count the time since the last 100ms tick, so a phase
+// shorter than a tick still records how long it ran.
+func TestPauseSkipCountPartialTick(t *testing.T) {
+	tm := New(DefaultConfig())
+	tm.Start()
+	time.Sleep(30 * time.Millisecond)
+	tm.Pause()
+	if got := tm.Remaining(); got > DefaultConfig().WorkDuration-30*time.Millisecond {
+		t.Errorf("remaining after pause = %v, want 30ms less than %v", got, DefaultConfig().WorkDuration)
+	}
+
+	tm.Resume()
+	time.Sleep(30 * time.Millisecond)
+	tm.Skip()
+	if _, elapsed := tm.LastPhase(); elapsed < 60*time.Millisecond {
+		t.Errorf("skipped phase ran %v, want at least 60ms", elapsed)
+	}
+}
@@ -1,79 +1,77 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/philleif/pomme/internal/sparkline"
+	"github.com/philleif/pomme/internal/theme"
+)
 
 var (
-	subtle    = lipgloss.AdaptiveColor{Light: "#D9DCCF", Dark: "#383838"}
-	highlight = lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"}
-	special   = lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"}
-
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FF6347")).
-			Align(lipgloss.Center)
-
-	timerStyleNormal = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#FF6347"))
-
-	counterStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#888888"))
-
-	timerBreakStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("#FFFFFF")).
-			Background(lipgloss.Color("#4A90D9"))
-
-	timerPausedStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("#FFFFFF")).
-				Background(lipgloss.Color("#888888"))
-
-	phaseStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#888888")).
-			Align(lipgloss.Center)
-
-	helpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#626262")).
-			Align(lipgloss.Center)
-
-	statsStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#AAAAAA"))
-
-	progressFullStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#FF6347"))
+	titleStyle         lipgloss.Style
+	counterStyle       lipgloss.Style
+	phaseStyle         lipgloss.Style
+	helpStyle          lipgloss.Style
+	statsStyle         lipgloss.Style
+	progressFullStyle  lipgloss.Style
+	progressEmptyStyle lipgloss.Style
+	sparklineStyle     lipgloss.Style
+	labelStyle         lipgloss.Style
+	goalStyle          lipgloss.Style
+	todayValueStyle    lipgloss.Style
+	toggleOnStyle      lipgloss.Style
+	toggleOffStyle     lipgloss.Style
+	currentTaskStyle   lipgloss.Style
+	interruptionStyle  lipgloss.Style
+	noteInputStyle     lipgloss.Style
+	errorStyle         lipgloss.Style
+	boxStyle           lipgloss.Style
 
-	progressEmptyStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("#333333"))
-
-	sparklineStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#73F59F"))
-
-	labelStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#666666"))
-
-	goalStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#555555"))
-
-	todayValueStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF6347")).
-			Bold(true)
-
-	toggleOnStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#73F59F")).
-			Bold(true)
+	// State-based colors for border and timer background
+	colorWorkActive  lipgloss.Color
+	colorBreakActive lipgloss.Color
+	colorPaused      lipgloss.Color
+	colorInactive    lipgloss.Color
 
-	toggleOffStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF6347"))
+	// Calendar cells
+	calendarPalette sparkline.Palette
+)
 
+func init() {
+	applyTheme(theme.Themes[theme.Default])
+}
+
+// applyTheme sets the styles from t.
+func applyTheme(t theme.Theme) {
+	fg := func(c string) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(c))
+	}
+
+	titleStyle = fg(t.Accent).Bold(true).Align(lipgloss.Center)
+	counterStyle = fg(t.Muted)
+	phaseStyle = fg(t.Muted).Align(lipgloss.Center)
+	helpStyle = fg(t.Subtle).Align(lipgloss.Center)
+	statsStyle = fg(t.Secondary)
+	progressFullStyle = fg(t.Accent)
+	progressEmptyStyle = fg(t.Faint)
+	sparklineStyle = fg(t.Good)
+	labelStyle = fg(t.Subtle)
+	goalStyle = fg(t.Subtle)
+	todayValueStyle = fg(t.Accent).Bold(true)
+	toggleOnStyle = fg(t.Good).Bold(true)
+	toggleOffStyle = fg(t.Accent)
+	currentTaskStyle = fg(t.Accent).Bold(true)
+	interruptionStyle = fg(t.Accent).Bold(true)
+	noteInputStyle = fg(t.Text).Width(38)
+	errorStyle = fg(t.Accent).Width(38)
 	boxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#FF6347")).
-			Padding(1, 2)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(t.Accent)).
+		Padding(1, 2)
 
-	// State-based colors for border and timer background
-	colorWorkActive  = lipgloss.Color("#00FF00") // bright green
-	colorBreakActive = lipgloss.Color("#2E5A2E") // pale dark green
-	colorInactive    = lipgloss.Color("#FF6347") // red
-)
+	colorWorkActive = lipgloss.Color(t.Work)
+	colorBreakActive = lipgloss.Color(t.Break)
+	colorPaused = lipgloss.Color(t.Paused)
+	colorInactive = lipgloss.Color(t.Idle)
+
+	calendarPalette = t.Palette()
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/philleif/pomme/internal/client"
 	"github.com/philleif/pomme/internal/daemon"
+	"github.com/philleif/pomme/internal/sparkline"
+	"github.com/philleif/pomme/internal/storage"
+	"github.com/philleif/pomme/internal/theme"
 )
 
 type tickMsg time.Time
@@ -16,22 +19,39 @@ type tickMsg time.Time
 type Model struct {
 	client *client.Client
 	status *daemon.StatusData
+	tasks  []storage.Task
 	err    error
 	width  int
 	height int
+
+	// Note prompt, opened with n or when a work interval completes.
+	noting    bool
+	noteInput string
+	noteMsg   string
+	lastCount int
+
+	// Yearly calendar screen, toggled with c.
+	showCalendar bool
+	calendar     []sparkline.CalendarDay
 }
 
 func NewModel() Model {
 	c := client.New()
 	status, err := c.Status()
+	tasks, _ := c.Tasks(false)
 
-	return Model{
+	m := Model{
 		client: c,
 		status: status,
+		tasks:  tasks,
 		err:    err,
 		width:  60,
 		height: 20,
 	}
+	if status != nil {
+		m.lastCount = status.IntervalsToday
+	}
+	return m
 }
 
 func (m Model) Init() tea.Cmd {
@@ -58,13 +78,45 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		status, err := m.client.Status()
 		m.status = status
 		m.err = err
+		m.tasks, _ = m.client.Tasks(false)
+		if status != nil {
+			if status.IntervalsToday > m.lastCount && !m.noting {
+				// A work interval just completed: ask what got done.
+				m.noting = true
+				m.noteInput = ""
+				m.noteMsg = ""
+			}
+			if m.showCalendar && status.IntervalsToday != m.lastCount {
+				m.calendar, _ = m.client.Calendar(m.calendarWeeks())
+			}
+			m.lastCount = status.IntervalsToday
+		}
 		return m, tickCmd()
 
 	case tea.KeyMsg:
+		if m.noting {
+			return m.updateNote(msg)
+		}
+
+		if m.showCalendar {
+			switch msg.String() {
+			case "q", "ctrl+c":
+				return m, tea.Quit
+			case "c", "esc":
+				m.showCalendar = false
+			}
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 
+		case "c":
+			m.showCalendar = true
+			m.calendar, _ = m.client.Calendar(m.calendarWeeks())
+			return m, nil
+
 		case "s":
 			m.client.Start()
 			status, _ := m.client.Status()
@@ -100,6 +152,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			status, _ := m.client.Status()
 			m.status = status
 			return m, nil
+
+		case "i", "e":
+			kind := "internal"
+			if msg.String() == "e" {
+				kind = "external"
+			}
+			if _, err := m.client.Interrupt(kind, ""); err != nil {
+				m.noteMsg = err.Error()
+			} else {
+				m.noteMsg = ""
+			}
+			status, _ := m.client.Status()
+			m.status = status
+			return m, nil
+
+		case "n":
+			m.noting = true
+			m.noteInput = ""
+			m.noteMsg = ""
+			return m, nil
+
+		case "t":
+			if id := m.nextTaskID(); id != 0 {
+				m.client.SelectTask(id)
+			}
+			status, _ := m.client.Status()
+			m.status = status
+			return m, nil
+
+		case "d":
+			if m.status != nil && m.status.CurrentTask != nil {
+				m.client.CompleteTask(m.status.CurrentTask.ID)
+			}
+			status, _ := m.client.Status()
+			m.status = status
+			m.tasks, _ = m.client.Tasks(false)
+			return m, nil
 		}
 	}
 
@@ -115,32 +204,42 @@ func (m Model) View() string {
 		return boxStyle.Render("Connecting...")
 	}
 
+	if m.showCalendar {
+		return m.renderCalendar()
+	}
+
 	var b strings.Builder
 
-	// Determine state color: bright green for work, pale dark green for break, red for inactive
+	// State color for the timer and border: work, break, paused or idle
 	var stateColor lipgloss.Color
 	switch {
 	case m.status.TimerState == "running" && m.status.Phase == "work":
 		stateColor = colorWorkActive
 	case m.status.TimerState == "running":
 		stateColor = colorBreakActive
+	case m.status.TimerState == "paused":
+		stateColor = colorPaused
 	default:
 		stateColor = colorInactive
 	}
 
 	// Header line: title | timer | counter (space-between)
-	timerFg := lipgloss.Color("#FFFFFF")
-	if m.status.TimerState == "running" {
-		timerFg = lipgloss.Color("#000000")
-	}
 	timerStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(timerFg).
+		Foreground(lipgloss.Color(theme.Contrast(string(stateColor)))).
 		Background(stateColor)
+	if stateColor == "" {
+		// No colors (NO_COLOR): set the timer off by reversing it instead.
+		timerStyle = timerStyle.Reverse(true)
+	}
 
 	title := titleStyle.Render("🍅 POMME")
 	timer := timerStyle.Render(fmt.Sprintf(" %s ", m.status.Remaining))
-	counter := counterStyle.Render(fmt.Sprintf("%d/%d", m.status.IntervalsToday, m.status.DailyGoal))
+	goal := fmt.Sprint(m.status.DailyGoal)
+	if m.status.DayOff && m.status.DailyGoal == 0 {
+		goal = "off"
+	}
+	counter := counterStyle.Render(fmt.Sprintf("%d/%s", m.status.IntervalsToday, goal))
 
 	headerWidth := 38
 	spacer := headerWidth - lipgloss.Width(title) - lipgloss.Width(timer) - lipgloss.Width(counter)
@@ -155,13 +254,26 @@ func (m Model) View() string {
 
 	help := helpStyle.Render("[s]tart  [p]ause  [k]ip  [r]eset")
 	b.WriteString(help)
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("[i]nternal / [e]xternal interruption"))
 	b.WriteString("\n\n")
 
+	if m.status.Interruptions != "" {
+		b.WriteString(labelStyle.Render("Interruptions: "))
+		b.WriteString(interruptionStyle.Render(m.status.Interruptions))
+		b.WriteString("\n\n")
+	}
+
+	if m.status.ConfigError != "" {
+		b.WriteString(errorStyle.Render(m.status.ConfigError))
+		b.WriteString("\n\n")
+	}
+
 	// Enhanced progress display with goal reference
 	progress := m.renderProgress(m.status.IntervalsToday, m.status.DailyGoal)
 	b.WriteString(statsStyle.Render(fmt.Sprintf("Today: %s %d", progress, m.status.IntervalsToday)))
 	b.WriteString("\n")
-	b.WriteString(goalStyle.Render(fmt.Sprintf("       %s goal:%d", strings.Repeat("─", 12), m.status.DailyGoal)))
+	b.WriteString(goalStyle.Render(fmt.Sprintf("       %s goal:%s", strings.Repeat("─", 12), goal)))
 	b.WriteString("\n\n")
 
 	// Enhanced sparkline with day labels and values
@@ -202,8 +314,19 @@ func (m Model) View() string {
 		b.WriteString(labelStyle.Render(strings.Repeat(" ", 7+todayPos*3) + "↑today"))
 		b.WriteString("\n")
 	}
+	if m.status.Streak > 0 {
+		b.WriteString(labelStyle.Render(fmt.Sprintf("       %d-day goal streak (best %d)", m.status.Streak, m.status.LongestStreak)))
+		b.WriteString("\n")
+	}
+	if m.status.WeekFocusMinutes > 0 {
+		b.WriteString(labelStyle.Render(fmt.Sprintf("       %s focused this week", formatMinutes(m.status.WeekFocusMinutes))))
+		b.WriteString("\n")
+	}
 	b.WriteString("\n")
 
+	b.WriteString(m.renderNote())
+	b.WriteString(m.renderTasks())
+
 	blockStatus := m.renderToggle("Block Messages", m.status.BlockEnabled, "b")
 	b.WriteString(blockStatus)
 	b.WriteString("\n")
@@ -212,7 +335,7 @@ func (m Model) View() string {
 	b.WriteString(alwaysStatus)
 	b.WriteString("\n\n")
 
-	b.WriteString(helpStyle.Render("[q]uit"))
+	b.WriteString(helpStyle.Render("[c]alendar  [q]uit"))
 
 	// Use state-colored border
 	dynamicBox := boxStyle.BorderForeground(stateColor)
@@ -221,8 +344,143 @@ func (m Model) View() string {
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
 }
 
+func (m Model) updateNote(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.noting = false
+	case tea.KeyEnter:
+		m.noting = false
+		if note := strings.TrimSpace(m.noteInput); note != "" {
+			if _, err := m.client.Note(note, nil); err != nil {
+				m.noteMsg = fmt.Sprintf("Note not saved: %v", err)
+			} else {
+				m.noteMsg = "Note saved"
+			}
+		}
+	case tea.KeyBackspace:
+		if r := []rune(m.noteInput); len(r) > 0 {
+			m.noteInput = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.noteInput += " "
+	case tea.KeyRunes:
+		m.noteInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+func (m Model) renderNote() string {
+	if m.noting {
+		prompt := statsStyle.Render("What did you get done? (#tags, enter/esc)")
+		input := noteInputStyle.Render("> " + m.noteInput + "█")
+		return prompt + "\n" + input + "\n\n"
+	}
+	if m.noteMsg != "" {
+		return labelStyle.Render(m.noteMsg) + "\n\n"
+	}
+	return ""
+}
+
+// nextTaskID cycles through active tasks after the current one.
+func (m Model) nextTaskID() int64 {
+	if len(m.tasks) == 0 {
+		return 0
+	}
+	if m.status == nil || m.status.CurrentTask == nil {
+		return m.tasks[0].ID
+	}
+	for i, t := range m.tasks {
+		if t.ID == m.status.CurrentTask.ID {
+			return m.tasks[(i+1)%len(m.tasks)].ID
+		}
+	}
+	return m.tasks[0].ID
+}
+
+func (m Model) renderTasks() string {
+	const maxTasks = 5
+
+	var b strings.Builder
+	b.WriteString(statsStyle.Render("Tasks"))
+	b.WriteString("\n")
+
+	if len(m.tasks) == 0 {
+		b.WriteString(labelStyle.Render("  pomme task add <title>"))
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("[n]ote"))
+		b.WriteString("\n\n")
+		return b.String()
+	}
+
+	var currentID int64
+	if m.status.CurrentTask != nil {
+		currentID = m.status.CurrentTask.ID
+	}
+
+	for i, t := range m.tasks {
+		if i == maxTasks {
+			b.WriteString(labelStyle.Render(fmt.Sprintf("  +%d more", len(m.tasks)-maxTasks)))
+			b.WriteString("\n")
+			break
+		}
+		progress := fmt.Sprintf("%d", t.Completed)
+		if t.Estimate > 0 {
+			progress = fmt.Sprintf("%d/%d", t.Completed, t.Estimate)
+		}
+		title := t.Title
+		if len([]rune(title)) > 28 {
+			title = string([]rune(title)[:27]) + "…"
+		}
+		line := fmt.Sprintf("%-28s %5s", title, progress)
+		if t.ID == currentID {
+			b.WriteString(currentTaskStyle.Render("▶ " + line))
+		} else {
+			b.WriteString(labelStyle.Render("  " + line))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(helpStyle.Render("[t]ask next  [d]one  [n]ote"))
+	b.WriteString("\n\n")
+	return b.String()
+}
+
+// calendarWeeks is how many weeks of the calendar fit the window, up to a
+// year.
+func (m Model) calendarWeeks() int {
+	return max(4, min(53, (m.width-14)/2))
+}
+
+func (m Model) renderCalendar() string {
+	var b strings.Builder
+	total := 0
+	for _, d := range m.calendar {
+		total += d.Count
+	}
+	b.WriteString(titleStyle.Render("🍅 POMME"))
+	b.WriteString(statsStyle.Render(fmt.Sprintf("  %d pomodoros in %d weeks", total, (len(m.calendar)+6)/7)))
+	b.WriteString("\n\n")
+	style := sparkline.CalendarANSI
+	if theme.NoColor() {
+		style = sparkline.CalendarBraille
+	}
+	b.WriteString(sparkline.RenderCalendar(m.calendar, style, calendarPalette))
+	b.WriteString("\n")
+	b.WriteString(labelStyle.Render("less "))
+	b.WriteString(strings.TrimPrefix(sparkline.CalendarLegend(style, calendarPalette), "less "))
+	b.WriteString("\n\n")
+	b.WriteString(helpStyle.Render("[c] back  [q]uit"))
+
+	content := boxStyle.Render(b.String())
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
 func (m Model) renderProgress(current, total int) string {
 	width := 12
+	if total <= 0 {
+		total = 1
+	}
 	filled := (current * width) / total
 	if filled > width {
 		filled = width
@@ -244,8 +502,18 @@ func (m Model) renderToggle(label string, on bool, key string) string {
 	return fmt.Sprintf("[%s] %s: %s", key, label, status)
 }
 
-func Run() error {
+// Run starts the TUI in theme t.
+func Run(t theme.Theme) error {
+	applyTheme(t)
 	p := tea.NewProgram(NewModel(), tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
+
+// formatMinutes renders minutes as "45m" or "3h05m".
+func formatMinutes(m int) string {
+	if m < 60 {
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%dh%02dm", m/60, m%60)
+}
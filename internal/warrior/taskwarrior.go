@@ -0,0 +1,76 @@
+package warrior
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var ErrNoTask = errors.New("no matching Taskwarrior task")
+
+// Task is the subset of a `task export` record pomme uses.
+type Task struct {
+	ID          int      `json:"id"`
+	UUID        string   `json:"uuid"`
+	Description string   `json:"description"`
+	Project     string   `json:"project,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Status      string   `json:"status"`
+	Urgency     float64  `json:"urgency"`
+}
+
+// TimewTags returns the tags Timewarrior's own on-modify hook would use for
+// the task: its description, project and tags.
+func (t Task) TimewTags() []string {
+	tags := []string{t.Description}
+	if t.Project != "" {
+		tags = append(tags, t.Project)
+	}
+	return append(tags, t.Tags...)
+}
+
+type Taskwarrior struct {
+	Runner Runner
+}
+
+func NewTaskwarrior() *Taskwarrior {
+	return &Taskwarrior{Runner: DefaultRunner}
+}
+
+// Pending returns pending tasks, most urgent first.
+func (tw *Taskwarrior) Pending() ([]Task, error) {
+	tasks, err := tw.export("status:pending")
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Urgency > tasks[j].Urgency
+	})
+	return tasks, nil
+}
+
+// Get returns the task with the given ID or UUID.
+func (tw *Taskwarrior) Get(ref string) (Task, error) {
+	tasks, err := tw.export(ref)
+	if err != nil {
+		return Task{}, err
+	}
+	if len(tasks) != 1 {
+		return Task{}, fmt.Errorf("%w: %s", ErrNoTask, ref)
+	}
+	return tasks[0], nil
+}
+
+func (tw *Taskwarrior) export(filter ...string) ([]Task, error) {
+	args := append([]string{"rc.verbose=nothing", "rc.confirmation=off"}, filter...)
+	out, err := tw.Runner.Run("task", append(args, "export")...)
+	if err != nil {
+		return nil, err
+	}
+	var tasks []Task
+	if err := json.Unmarshal(out, &tasks); err != nil {
+		return nil, fmt.Errorf("task export: %w", err)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,34 @@
+package warrior
+
+import (
+	"strings"
+)
+
+type Timewarrior struct {
+	Runner Runner
+}
+
+func NewTimewarrior() *Timewarrior {
+	return &Timewarrior{Runner: DefaultRunner}
+}
+
+// Start begins tracking a new interval with tags, ending any open one.
+func (tw *Timewarrior) Start(tags []string) error {
+	args := []string{"start"}
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			args = append(args, tag)
+		}
+	}
+	_, err := tw.Runner.Run("timew", append(args, ":quiet")...)
+	return err
+}
+
+// Stop ends the open interval. It is not an error if nothing is tracked.
+func (tw *Timewarrior) Stop() error {
+	_, err := tw.Runner.Run("timew", "stop", ":quiet")
+	if err != nil && strings.Contains(err.Error(), "no active time tracking") {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+// Package warrior talks to Taskwarrior (`task`) and Timewarrior (`timew`)
+// through their command-line tools. Commands go through a Runner so the
+// binaries can be replaced, e.g. by a stub that records calls.
+package warrior
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Runner runs an external command and returns its standard output.
+type Runner interface {
+	Run(name string, args ...string) ([]byte, error)
+}
+
+// ExecRunner runs commands from $PATH, giving up after Timeout.
+type ExecRunner struct {
+	Timeout time.Duration
+}
+
+// DefaultRunner is used by New.
+var DefaultRunner Runner = ExecRunner{Timeout: 5 * time.Second}
+
+func (r ExecRunner) Run(name string, args ...string) ([]byte, error) {
+	ctx := context.Background()
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.Bytes(), fmt.Errorf("%s: %s", name, msg)
+		}
+		return stdout.Bytes(), fmt.Errorf("%s: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
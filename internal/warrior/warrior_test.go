@@ -0,0 +1,120 @@
+package warrior
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeRunner records commands and answers them with canned output.
+type fakeRunner struct {
+	calls  []string
+	output string
+	err    error
+}
+
+func (f *fakeRunner) Run(name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, name+" "+strings.Join(args, " "))
+	return []byte(f.output), f.err
+}
+
+func TestTimewarriorStart(t *testing.T) {
+	r := &fakeRunner{}
+	tw := &Timewarrior{Runner: r}
+	if err := tw.Start([]string{"Write docs", " ", " pomme "}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Start(nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"timew start Write docs pomme :quiet", "timew start :quiet"}
+	if !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+
+	r.err = errors.New("timew: database is locked")
+	if err := tw.Start(nil); err == nil {
+		t.Error("Start hid an error")
+	}
+}
+
+func TestTimewarriorStop(t *testing.T) {
+	r := &fakeRunner{}
+	tw := &Timewarrior{Runner: r}
+	if err := tw.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"timew stop :quiet"}; !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+
+	r.err = errors.New("timew: There is no active time tracking.")
+	if err := tw.Stop(); err != nil {
+		t.Errorf("Stop with nothing tracked = %v, want nil", err)
+	}
+	r.err = errors.New("timew: executable file not found in $PATH")
+	if err := tw.Stop(); err == nil {
+		t.Error("Stop hid an error")
+	}
+}
+
+const exportJSON = `[
+	{"id": 1, "uuid": "a1", "description": "Low", "status": "pending", "urgency": 1.5},
+	{"id": 2, "uuid": "b2", "description": "High", "project": "pomme", "tags": ["docs"], "status": "pending", "urgency": 9.1},
+	{"id": 3, "uuid": "c3", "description": "Mid", "status": "pending", "urgency": 4}
+]`
+
+func TestTaskwarriorPending(t *testing.T) {
+	r := &fakeRunner{output: exportJSON}
+	tw := &Taskwarrior{Runner: r}
+	tasks, err := tw.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, task := range tasks {
+		order = append(order, task.Description)
+	}
+	if want := []string{"High", "Mid", "Low"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want most urgent first %v", order, want)
+	}
+	if want := []string{"task rc.verbose=nothing rc.confirmation=off status:pending export"}; !reflect.DeepEqual(r.calls, want) {
+		t.Errorf("calls = %q, want %q", r.calls, want)
+	}
+
+	r.output = "not json"
+	if _, err := tw.Pending(); err == nil {
+		t.Error("Pending accepted invalid output")
+	}
+}
+
+func TestTaskwarriorGet(t *testing.T) {
+	r := &fakeRunner{output: `[{"id": 2, "uuid": "b2", "description": "High", "project": "pomme", "tags": ["docs"], "status": "pending"}]`}
+	tw := &Taskwarrior{Runner: r}
+	task, err := tw.Get("b2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.UUID != "b2" {
+		t.Errorf("task = %+v", task)
+	}
+	if want := []string{"High", "pomme", "docs"}; !reflect.DeepEqual(task.TimewTags(), want) {
+		t.Errorf("TimewTags = %q, want %q", task.TimewTags(), want)
+	}
+	if want := "task rc.verbose=nothing rc.confirmation=off b2 export"; r.calls[0] != want {
+		t.Errorf("call = %q, want %q", r.calls[0], want)
+	}
+
+	for _, output := range []string{"[]", exportJSON} {
+		r.output = output
+		if _, err := tw.Get("x"); !errors.Is(err, ErrNoTask) {
+			t.Errorf("Get with %d-byte output = %v, want ErrNoTask", len(output), err)
+		}
+	}
+
+	r.err = errors.New("task: not found")
+	if _, err := tw.Get("b2"); err == nil || errors.Is(err, ErrNoTask) {
+		t.Errorf("Get = %v, want the runner's error", err)
+	}
+}